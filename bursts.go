@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"sync"
+	"time"
+)
+
+var burstWindow = flag.Duration("burst-window", 5*time.Second, "wait `duration` for related events, like the push after a pull request is merged, to announce them together (0 to announce every event on its own)")
+
+// An event, along with its type.
+type BurstEvent struct {
+	Type  string
+	Event *GHEvent
+}
+
+// A BurstCoalescer holds on to events which tend to come in bursts,
+// so that related events can be announced together.
+// Merging a pull request, for instance, sends a pull_request event,
+// a push to the base branch, and often a push and a delete event
+// for the head branch; see FormatGithubEvents.
+//
+// The first such event for a repository starts the clock;
+// once the window has passed, all the events received
+// for the repository since then are reported together.
+type BurstCoalescer struct {
+	Window time.Duration
+	// Called from a timer's goroutine once a burst is over.
+	Report func(events []BurstEvent)
+
+	mu      sync.Mutex
+	pending map[string][]BurstEvent
+}
+
+// Add an event to a burst.
+// Reports whether the event was held on to;
+// if not, it should be reported as usual.
+func (c *BurstCoalescer) Add(eventType string, event *GHEvent) bool {
+	if c == nil || c.Window <= 0 || !burstable(eventType, event) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[string][]BurstEvent)
+	}
+	key := event.Repository.FullName
+	if _, ok := c.pending[key]; !ok {
+		time.AfterFunc(c.Window, func() { c.flush(key) })
+	}
+	c.pending[key] = append(c.pending[key], BurstEvent{eventType, event})
+	return true
+}
+
+func (c *BurstCoalescer) flush(key string) {
+	c.mu.Lock()
+	events := c.pending[key]
+	delete(c.pending, key)
+	c.mu.Unlock()
+
+	if len(events) > 0 {
+		c.Report(events)
+	}
+}
+
+// Reports whether an event might be part of a burst.
+func burstable(eventType string, event *GHEvent) bool {
+	switch eventType {
+	case "push", "delete":
+		return true
+	case "pull_request":
+		return event.merged()
+	}
+	return false
+}
+
+// Reports whether an event is about a pull request being merged.
+func (event *GHEvent) merged() bool {
+	return event.Action == "closed" && event.PullRequest != nil && event.PullRequest.Merged
+}
+
+// Reports whether an event deletes the named branch.
+func (event *GHEvent) deletesBranch(eventType, branch string) bool {
+	switch eventType {
+	case "push":
+		return event.deleted() && event.Ref == "refs/heads/"+branch
+	case "delete":
+		return event.RefType == "branch" && event.Ref == branch
+	}
+	return false
+}
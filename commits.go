@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+)
+
+// GitHub commits changes made on the website, and pull requests merged there,
+// as itself; those aren't worth pointing out.
+const githubCommitterEmail = "noreply@github.com"
+
+// The name of whoever committed a commit, if they aren't its author,
+// as when someone rebases or cherry-picks someone else's commits.
+func (commit *GHCommit) otherCommitter() string {
+	a, c := commit.Author, commit.Committer
+	if c.Name == "" || c.Email == githubCommitterEmail {
+		return ""
+	}
+	if a.Username != "" && c.Username != "" {
+		if a.Username == c.Username {
+			return ""
+		}
+	} else if a.Name == c.Name || (a.Email != "" && a.Email == c.Email) {
+		return ""
+	}
+	return c.Name
+}
+
+// How long ago a commit was authored, if it was at least days ago.
+// Returns false if the commit is newer or has no timestamp.
+func (commit *GHCommit) age(now time.Time, days int) (time.Duration, bool) {
+	if days <= 0 {
+		return 0, false
+	}
+	authored, err := time.Parse(time.RFC3339, commit.Timestamp)
+	if err != nil {
+		return 0, false
+	}
+	d := now.Sub(authored)
+	return d, d >= time.Duration(days)*24*time.Hour
+}
+
+// Rounds an age down to a number of days, weeks, months or years,
+// whichever reads best.
+func ageUnits(d time.Duration) (n int, unit string) {
+	days := int(d / (24 * time.Hour))
+	switch {
+	case days < 14:
+		return days, "day"
+	case days < 60:
+		return days / 7, "week"
+	case days < 365:
+		return days / 30, "month"
+	default:
+		return days / 365, "year"
+	}
+}
+
+// A description of how long ago a commit was authored, like "3 weeks ago",
+// if it's older than data.OldCommitDays.
+func (data *EventFormatterOptions) commitAge(commit *GHCommit) string {
+	d, ok := commit.age(time.Now(), data.OldCommitDays)
+	if !ok {
+		return ""
+	}
+	return data.catalog().ago(ageUnits(d))
+}
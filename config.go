@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config is the contents of the file passed with -config.
+//
+// Example:
+//
+//	{
+//		"Destinations": [
+//			{
+//				"Channel": "#security",
+//				"Events": ["dependabot_alert", "code_scanning_alert"]
+//			}
+//		],
+//		"AuditChannel": "#audit",
+//		"Shortener": {"Type": "builtin", "BaseURL": "https://hooks.example.com", "File": "links.txt"}
+//	}
+type Config struct {
+	// Additional channels to announce events to,
+	// besides the one given in the -irc url.
+	// A destination for the -irc channel itself takes the place of its usual feed,
+	// which is how to give the default channel options or rules.
+	Destinations []*Destination
+
+	// A channel to announce changes to repository access and settings to.
+	// Shorthand for a destination which lists all the audit events.
+	AuditChannel string
+
+	// How to shorten URLs for destinations which don't set LongURL.
+	// By default, URLs aren't shortened.
+	Shortener *ShortenerConfig
+
+	// The IRC nicks of GitHub users, by login.
+	// Users are shown by their nick instead of their login.
+	Nicks map[string]string
+}
+
+// A Destination is a channel that events are announced to.
+type Destination struct {
+	// The channel to announce events to.
+	Channel string
+
+	// Event types to announce.
+	// If empty, all events are announced except for opt-in events.
+	Events []string
+
+	// Rules for which events to announce, checked in order.
+	// See FilterGithubEvent.
+	Rules []*FilterRule
+
+	// Options for formatting events.
+	// If nil, the default options are used.
+	Options *EventFormatterOptions
+
+	// If set, events are summed up in a digest
+	// instead of being announced as they happen.
+	Digest *DigestConfig
+}
+
+// Opt-in events are only announced to destinations that list them in Events.
+// See init for what they are.
+var optInEvents = map[string]bool{}
+
+// Security alerts may disclose unfixed vulnerabilities,
+// so they should never end up in a public channel by accident.
+// They are opt-in, and can't be sent to the default channel at all.
+var securityEvents = map[string]bool{
+	"dependabot_alert":      true,
+	"code_scanning_alert":   true,
+	"secret_scanning_alert": true,
+	"repository_advisory":   true,
+}
+
+// Audit events record changes to who can access a repository and how.
+// They are opt-in, so that they can be kept separate from the developer channel.
+var auditEvents = []string{
+	"member",
+	"repository",
+	"public",
+	"branch_protection_rule",
+	"team_add",
+	"organization",
+	"membership",
+	"installation",
+}
+
+// Triage events are mostly interesting to people who go through the issue tracker.
+// They are opt-in too.
+var triageEvents = []string{
+	"milestone",
+	"label",
+	"projects_v2_item",
+}
+
+func init() {
+	for e := range securityEvents {
+		optInEvents[e] = true
+	}
+	for _, e := range auditEvents {
+		optInEvents[e] = true
+	}
+	for _, e := range triageEvents {
+		optInEvents[e] = true
+	}
+}
+
+func loadConfig(filename string) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg := new(Config)
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Make sure the config is sane.
+// Channel names are normalized as a side effect,
+// and the AuditChannel is turned into a destination.
+func (cfg *Config) check(defaultChannel string) error {
+	if cfg.AuditChannel != "" {
+		if sameChannel(cfg.AuditChannel, defaultChannel) {
+			return fmt.Errorf("the audit channel must be separate from the default channel (%s)", channelName(defaultChannel))
+		}
+		cfg.Destinations = append(cfg.Destinations, &Destination{
+			Channel: cfg.AuditChannel,
+			Events:  auditEvents,
+		})
+		cfg.AuditChannel = ""
+	}
+	defaults := 0
+	for i, d := range cfg.Destinations {
+		if d.Channel == "" {
+			return fmt.Errorf("destination %d: missing channel", i+1)
+		}
+		d.Channel = channelName(d.Channel)
+		if d.Options != nil {
+			if err := d.Options.check(); err != nil {
+				return fmt.Errorf("destination %d: %v", i+1, err)
+			}
+		}
+		if d.Digest != nil {
+			if err := d.Digest.check(); err != nil {
+				return fmt.Errorf("destination %d: %v", i+1, err)
+			}
+		}
+		for j, r := range d.Rules {
+			if err := r.check(); err != nil {
+				return fmt.Errorf("destination %d: rule %d: %v", i+1, j+1, err)
+			}
+		}
+		if !sameChannel(d.Channel, defaultChannel) {
+			continue
+		}
+		if defaults++; defaults > 1 {
+			return fmt.Errorf("destination %d: the default channel (%s) is already configured", i+1, channelName(defaultChannel))
+		}
+		for _, e := range d.Events {
+			if securityEvents[e] {
+				return fmt.Errorf("destination %d: %s events cannot be sent to the default channel (%s)", i+1, e, d.Channel)
+			}
+		}
+	}
+	return nil
+}
+
+// The destinations to announce events to.
+// The default channel gets every event that isn't opt-in,
+// unless one of the configured destinations is for the default channel,
+// in which case that destination is used instead.
+func (cfg *Config) destinations(defaultChannel string) []*Destination {
+	dests := cfg.Destinations
+	for _, d := range dests {
+		if sameChannel(d.Channel, defaultChannel) {
+			return dests
+		}
+	}
+	return append([]*Destination{{Channel: defaultChannel}}, dests...)
+}
+
+// Make sure the formatting options are sane.
+func (data *EventFormatterOptions) check() error {
+	switch data.BotMode {
+	case "", "ignore", "summarize", "show":
+	default:
+		return fmt.Errorf("unknown BotMode %q", data.BotMode)
+	}
+	for _, f := range []struct{ name, list string }{
+		{"Branches", data.Branches},
+		{"Tags", data.Tags},
+		{"Paths", data.Paths},
+		{"Environments", data.Environments},
+	} {
+		if err := checkListFilter(f.list); err != nil {
+			return fmt.Errorf("%s: %v", f.name, err)
+		}
+	}
+	if data.EditWindow != "" {
+		if d, err := time.ParseDuration(data.EditWindow); err != nil || d < 0 {
+			return fmt.Errorf("bad EditWindow %q", data.EditWindow)
+		}
+	}
+	if data.OldCommitDays < 0 {
+		return fmt.Errorf("bad OldCommitDays %d", data.OldCommitDays)
+	}
+	switch data.RepoNames {
+	case "", "name", "full":
+	default:
+		return fmt.Errorf("unknown RepoNames %q", data.RepoNames)
+	}
+	if _, ok := renderers[data.Format]; !ok && data.Format != "" {
+		return fmt.Errorf("unknown Format %q", data.Format)
+	}
+	if _, err := data.ircTheme(); err != nil {
+		return err
+	}
+	if err := data.checkLocale(); err != nil {
+		return err
+	}
+	return data.checkTemplates()
+}
+
+// Make sure every pattern in a comma-separated list is sane.
+// See listFilterMatches.
+func checkListFilter(filter string) error {
+	for _, p := range strings.Split(filter, ",") {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "!")
+		if p == "" {
+			continue
+		}
+		if err := checkPattern(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reports whether events of the given type should be announced to d.
+func (d *Destination) wants(eventType string) bool {
+	if len(d.Events) == 0 {
+		return !optInEvents[eventType]
+	}
+	for _, e := range d.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var digestFile = flag.String("digest-file", "digests.json", "`file` to keep digests in until they're posted")
+
+// DigestConfig says when to post a digest.
+type DigestConfig struct {
+	// "daily" or "weekly".
+	Every string
+	// The time of day to post the digest, like "09:00" (default midnight).
+	At string
+	// weekly: the day of the week to post the digest (default "Monday").
+	Day string
+	// The time zone At and Day are in, like "Europe/Berlin" (default local time).
+	TimeZone string
+
+	hour, min int
+	day       time.Weekday
+	loc       *time.Location
+}
+
+func (c *DigestConfig) check() error {
+	switch c.Every {
+	case "daily", "weekly":
+	default:
+		return fmt.Errorf("unknown digest period %q", c.Every)
+	}
+	if c.At != "" {
+		at, err := time.Parse("15:04", c.At)
+		if err != nil {
+			return fmt.Errorf("bad digest time %q", c.At)
+		}
+		c.hour, c.min = at.Hour(), at.Minute()
+	}
+	c.day = time.Monday
+	if c.Day != "" {
+		day, ok := weekdays[strings.ToLower(c.Day)]
+		if !ok {
+			return fmt.Errorf("unknown day %q", c.Day)
+		}
+		c.day = day
+	}
+	if c.TimeZone != "" {
+		loc, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return err
+		}
+		c.loc = loc
+	}
+	return nil
+}
+
+var weekdays = make(map[string]time.Weekday)
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		weekdays[strings.ToLower(d.String())] = d
+	}
+}
+
+// The first time a digest is due after t.
+func (c *DigestConfig) next(t time.Time) time.Time {
+	loc := c.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	local := t.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), c.hour, c.min, 0, 0, loc)
+	days := 1
+	if c.Every == "weekly" {
+		days = 7
+		next = next.AddDate(0, 0, (int(c.day)-int(next.Weekday())+7)%7)
+	}
+	for !next.After(t) {
+		next = next.AddDate(0, 0, days)
+	}
+	return next
+}
+
+// What happened in a repository since the last digest.
+type DigestTally struct {
+	Commits      int
+	Authors      map[string]bool
+	PullsOpened  int
+	PullsMerged  int
+	IssuesOpened int
+	IssuesClosed int
+	Releases     int
+}
+
+func (t *DigestTally) empty() bool {
+	return t.Commits == 0 && t.PullsOpened == 0 && t.PullsMerged == 0 &&
+		t.IssuesOpened == 0 && t.IssuesClosed == 0 && t.Releases == 0
+}
+
+// Count an event.
+// Reports whether it was the sort of thing digests count.
+func (t *DigestTally) add(eventType string, event *GHEvent, cfg *EventFormatterOptions) bool {
+	switch {
+	case eventType == "push":
+		if !cfg.branchNameMatches(event) {
+			return false
+		}
+		commits, skipped := getDistinctCommits(event, cfg)
+		if skipped {
+			return false
+		}
+		for _, c := range commits {
+			t.Commits++
+			if t.Authors == nil {
+				t.Authors = make(map[string]bool)
+			}
+			t.Authors[c.Author.Name] = true
+		}
+	case eventType == "pull_request" && event.Action == "opened":
+		t.PullsOpened++
+	case eventType == "pull_request" && event.merged():
+		t.PullsMerged++
+	case eventType == "issues" && event.Action == "opened":
+		t.IssuesOpened++
+	case eventType == "issues" && event.Action == "closed":
+		t.IssuesClosed++
+	case eventType == "release" && event.Action == "published":
+		t.Releases++
+	default:
+		return false
+	}
+	return true
+}
+
+// A Digester collects events for destinations that want a digest
+// instead of a live feed, and posts the digests when they're due.
+//
+// Digests are saved to a file as they're collected,
+// so that they survive restarts.
+type Digester struct {
+	Filename string
+	Announce func(d *Destination, msg string)
+
+	mu      sync.Mutex
+	digests map[string]*digest // by channel
+}
+
+type digest struct {
+	Since time.Time
+	Repos map[string]*DigestTally
+}
+
+// Load the saved digests, and schedule the digests for dests.
+// Digests that came due while the bot wasn't running are posted right away.
+func (g *Digester) Start(dests []*Destination) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	saved := make(map[string]*digest)
+	data, err := ioutil.ReadFile(g.Filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("%s: %v", g.Filename, err)
+		}
+	}
+	now := time.Now()
+	g.digests = make(map[string]*digest)
+	for _, d := range dests {
+		if d.Digest == nil {
+			continue
+		}
+		dg := saved[d.Channel]
+		if dg == nil {
+			dg = &digest{Since: now}
+		}
+		if dg.Repos == nil {
+			dg.Repos = make(map[string]*DigestTally)
+		}
+		g.digests[d.Channel] = dg
+		due := d.Digest.next(dg.Since)
+		if due.Before(now) {
+			due = now
+		}
+		d := d
+		time.AfterFunc(due.Sub(now), func() { g.post(d) })
+	}
+	return nil
+}
+
+// Add an event to the digest for d.
+// Reports whether d gets a digest;
+// if not, the event should be announced as usual.
+func (g *Digester) Add(d *Destination, eventType string, event *GHEvent) bool {
+	if g == nil || d.Digest == nil {
+		return false
+	}
+	cfg := d.Options
+	if cfg == nil {
+		cfg = &EventFormatterOptions{}
+	}
+	if cfg.ignoresSender(eventType, event) {
+		return true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	dg := g.digests[d.Channel]
+	if dg == nil {
+		return true
+	}
+	repo := event.Repository.FullName
+	t := dg.Repos[repo]
+	if t == nil {
+		t = new(DigestTally)
+	}
+	if t.add(eventType, event, cfg) {
+		dg.Repos[repo] = t
+		g.save()
+	}
+	return true
+}
+
+func (g *Digester) post(d *Destination) {
+	g.mu.Lock()
+	dg := g.digests[d.Channel]
+	now := time.Now()
+	g.digests[d.Channel] = &digest{Since: now, Repos: make(map[string]*DigestTally)}
+	g.save()
+	g.mu.Unlock()
+
+	repos := make([]string, 0, len(dg.Repos))
+	for repo := range dg.Repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	for _, repo := range repos {
+		if t := dg.Repos[repo]; !t.empty() {
+			g.Announce(d, FormatDigest(repo, t, d.Digest.Every, d.Options))
+		}
+	}
+
+	time.AfterFunc(d.Digest.next(now).Sub(now), func() { g.post(d) })
+}
+
+// Write the digests to the file.
+// Errors are logged; the digests are still kept in memory.
+func (g *Digester) save() {
+	data, err := json.Marshal(g.digests)
+	if err == nil {
+		tmp := g.Filename + ".tmp"
+		err = ioutil.WriteFile(tmp, data, 0644)
+		if err == nil {
+			err = os.Rename(tmp, g.Filename)
+		}
+	}
+	if err != nil {
+		botLog.Printf("error saving digests: %v", err)
+	}
+}
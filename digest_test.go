@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDigestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, berlin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		cfg  DigestConfig
+		now  string
+		want string
+	}{
+		// 2024-03-06 is a Wednesday.
+		{DigestConfig{Every: "daily"}, "2024-03-06 12:00", "2024-03-07 00:00"},
+		{DigestConfig{Every: "daily", At: "09:00"}, "2024-03-06 08:59", "2024-03-06 09:00"},
+		{DigestConfig{Every: "daily", At: "09:00"}, "2024-03-06 09:00", "2024-03-07 09:00"},
+		{DigestConfig{Every: "daily", At: "09:00"}, "2024-12-31 10:00", "2025-01-01 09:00"},
+		{DigestConfig{Every: "weekly"}, "2024-03-06 12:00", "2024-03-11 00:00"},
+		{DigestConfig{Every: "weekly", Day: "wednesday", At: "18:30"}, "2024-03-06 12:00", "2024-03-06 18:30"},
+		{DigestConfig{Every: "weekly", Day: "Wednesday", At: "18:30"}, "2024-03-06 18:30", "2024-03-13 18:30"},
+		{DigestConfig{Every: "weekly", Day: "Sunday"}, "2024-03-09 23:59", "2024-03-10 00:00"},
+		// Across the switch to summer time, which skips 02:00-03:00 on 2024-03-31.
+		{DigestConfig{Every: "daily", At: "12:00"}, "2024-03-30 12:00", "2024-03-31 12:00"},
+	}
+	for _, tt := range tests {
+		cfg := tt.cfg
+		cfg.TimeZone = "Europe/Berlin"
+		if err := cfg.check(); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.next(at(tt.now)); !got.Equal(at(tt.want)) {
+			t.Errorf("%+v: next(%s) = %s, want %s", tt.cfg, tt.now, got.In(berlin).Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+
+	// The time zone decides when the day starts.
+	cfg := DigestConfig{Every: "daily", TimeZone: "America/New_York"}
+	if err := cfg.check(); err != nil {
+		t.Skip(err)
+	}
+	if got, want := cfg.next(at("2024-03-06 12:00")), at("2024-03-07 06:00"); !got.Equal(want) {
+		t.Errorf("New York midnight: got %s, want %s", got.In(berlin), want)
+	}
+}
+
+func TestDigestConfigCheck(t *testing.T) {
+	for _, cfg := range []DigestConfig{
+		{Every: "hourly"},
+		{Every: "daily", At: "25:00"},
+		{Every: "weekly", Day: "Someday"},
+		{Every: "daily", TimeZone: "Nowhere/Nothing"},
+	} {
+		if err := cfg.check(); err == nil {
+			t.Errorf("%+v: no error", cfg)
+		}
+	}
+}
+
+// A digest that came due while the bot was down is posted when it starts.
+func TestDigesterOverdue(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "digests.json")
+	since := time.Now().AddDate(0, 0, -3).UTC().Format(time.RFC3339)
+	saved := `{"#digest":{"Since":"` + since + `","Repos":{"acme/app":{"Commits":3,"Authors":{"alice":true},"PullsMerged":1}}}}`
+	if err := ioutil.WriteFile(filename, []byte(saved), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	posted := make(chan string, 1)
+	g := &Digester{
+		Filename: filename,
+		Announce: func(d *Destination, msg string) {
+			posted <- msg
+		},
+	}
+	d := &Destination{Channel: "#digest", Digest: &DigestConfig{Every: "daily"}, Options: &EventFormatterOptions{NoColors: true}}
+	if err := d.Digest.check(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Start([]*Destination{d}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-posted:
+		if !strings.Contains(msg, "3 commits") {
+			t.Errorf("got %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the overdue digest wasn't posted")
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "acme/app") {
+		t.Errorf("the posted digest is still saved: %s", data)
+	}
+}
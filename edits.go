@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// How long after something is posted edits to it are ignored.
+// See EventFormatterOptions.EditWindow.
+func (data *EventFormatterOptions) editWindow() time.Duration {
+	if data.EditWindow == "" {
+		return 10 * time.Minute
+	}
+	d, err := time.ParseDuration(data.EditWindow)
+	if err != nil {
+		return 10 * time.Minute
+	}
+	return d
+}
+
+// Reports whether an edit is worth announcing.
+// Someone fixing a typo right after they posted something is just noise,
+// but going back a week later to change it isn't.
+// Edits are ignored if the timestamps are missing.
+func (data *EventFormatterOptions) lateEdit(createdAt, updatedAt string) bool {
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return false
+	}
+	updated, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return false
+	}
+	return updated.Sub(created) >= data.editWindow()
+}
+
+// Reports whether an event is an edit of the body of a comment, issue or pull request.
+func (event *GHEvent) bodyEdited() bool {
+	return event.Action == "edited" && event.Changes != nil && event.Changes.Body != nil
+}
+
+// The number of lines an edit added to and removed from a body.
+// Blank lines don't count, and neither does moving lines around.
+func (event *GHEvent) bodyDiff(body string) (added, removed int) {
+	if !event.bodyEdited() {
+		return 0, 0
+	}
+	lines := make(map[string]int)
+	for _, line := range bodyLines(event.Changes.Body.From) {
+		lines[line]--
+	}
+	for _, line := range bodyLines(body) {
+		lines[line]++
+	}
+	for _, n := range lines {
+		if n > 0 {
+			added += n
+		} else {
+			removed -= n
+		}
+	}
+	return added, removed
+}
+
+func bodyLines(body string) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Create a message about an edited body, with a summary of what changed.
+func (data *EventFormatterOptions) newEditMessage(eventType string, event *GHEvent, url, body string) *Message {
+	m := data.newMessage(eventType, event, url)
+	m.Added, m.Removed = event.bodyDiff(body)
+	return m
+}
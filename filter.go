@@ -0,0 +1,299 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var explain = flag.Bool("explain", false, "log which filter rule decided whether each event was announced")
+
+// A FilterRule decides whether the events it matches are announced.
+//
+// A rule matches an event if every field that is set matches.
+// Fields which are lists match if any entry in the list matches.
+// Patterns are globs, or regular expressions if they are surrounded by slashes,
+// like "/^release-[0-9.]+$/". See globToRegexp for the glob syntax.
+type FilterRule struct {
+	// Drop matching events instead of announcing them.
+	Exclude bool
+
+	Events   []string // event types, like "push"
+	Actions  []string // event actions, like "opened"
+	Repos    []string // patterns matching the repository's full name (or name, if the pattern has no slash)
+	Branches []string // patterns matching the pushed branch or the base branch of a pull request
+	Tags     []string // patterns matching the pushed tag
+	Senders  []string // logins of the user who triggered the event
+	Labels   []string // labels of the issue or pull request
+	Draft    *bool    // whether the pull request is a draft
+}
+
+// Decide whether to announce an event by checking it against a list of rules.
+// The first matching rule wins.
+// Events which don't match any rule are announced.
+//
+// Returns the index of the matching rule, or -1 if no rule matched.
+func FilterGithubEvent(eventType string, event *GHEvent, rules []*FilterRule) (announce bool, rule int) {
+	for i, r := range rules {
+		if r.matches(eventType, event) {
+			return !r.Exclude, i
+		}
+	}
+	return true, -1
+}
+
+func (r *FilterRule) matches(eventType string, event *GHEvent) bool {
+	if len(r.Events) > 0 && !anyEqual(r.Events, eventType) {
+		return false
+	}
+	if len(r.Actions) > 0 && !anyEqual(r.Actions, event.Action) {
+		return false
+	}
+	if len(r.Repos) > 0 && !r.repoMatches(event) {
+		return false
+	}
+	if len(r.Branches) > 0 {
+		branch, ok := event.branch()
+		if !ok || !anyPatternMatches(r.Branches, branch) {
+			return false
+		}
+	}
+	if len(r.Tags) > 0 {
+		tag, ok := event.tag()
+		if !ok || !anyPatternMatches(r.Tags, tag) {
+			return false
+		}
+	}
+	if len(r.Senders) > 0 && !anyEqualFold(r.Senders, event.Sender.Login) {
+		return false
+	}
+	if len(r.Labels) > 0 && !r.labelsMatch(event) {
+		return false
+	}
+	if r.Draft != nil {
+		if event.PullRequest == nil || event.PullRequest.Draft != *r.Draft {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *FilterRule) repoMatches(event *GHEvent) bool {
+	for _, p := range r.Repos {
+		name := event.Repository.FullName
+		if !isRegexpPattern(p) && !strings.Contains(p, "/") {
+			name = event.Repository.Name
+		}
+		if name != "" && matchPattern(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FilterRule) labelsMatch(event *GHEvent) bool {
+	var labels []GHLabel
+	if event.Issue != nil {
+		labels = event.Issue.Labels
+	} else if event.PullRequest != nil {
+		labels = event.PullRequest.Labels
+	}
+	for _, l := range labels {
+		if anyEqualFold(r.Labels, l.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Make sure all the patterns in the rule are valid.
+func (r *FilterRule) check() error {
+	for _, list := range [][]string{r.Repos, r.Branches, r.Tags} {
+		for _, p := range list {
+			if err := checkPattern(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Explain the result of FilterGithubEvent, for the logs.
+func explainFilter(rules []*FilterRule, announce bool, rule int) string {
+	verdict := "announced"
+	if !announce {
+		verdict = "excluded"
+	}
+	if rule < 0 {
+		return fmt.Sprintf("%s (no rule matched)", verdict)
+	}
+	return fmt.Sprintf("%s by rule %d: %s", verdict, rule+1, rules[rule])
+}
+
+func (r *FilterRule) String() string {
+	var parts []string
+	if r.Exclude {
+		parts = append(parts, "exclude")
+	} else {
+		parts = append(parts, "include")
+	}
+	add := func(name string, list []string) {
+		if len(list) > 0 {
+			parts = append(parts, name+"="+strings.Join(list, ","))
+		}
+	}
+	add("events", r.Events)
+	add("actions", r.Actions)
+	add("repos", r.Repos)
+	add("branches", r.Branches)
+	add("tags", r.Tags)
+	add("senders", r.Senders)
+	add("labels", r.Labels)
+	if r.Draft != nil {
+		parts = append(parts, fmt.Sprintf("draft=%v", *r.Draft))
+	}
+	return strings.Join(parts, " ")
+}
+
+// The branch an event is about.
+func (event *GHEvent) branch() (string, bool) {
+	if event.PullRequest != nil {
+		return event.PullRequest.Base.Ref, true
+	}
+	if event.Deployment != nil {
+		return event.Deployment.Ref, true
+	}
+	if event.WorkflowRun != nil {
+		return event.WorkflowRun.HeadBranch, true
+	}
+	if strings.HasPrefix(event.Ref, "refs/heads/") {
+		return strings.TrimPrefix(event.Ref, "refs/heads/"), true
+	}
+	return "", false
+}
+
+// The tag an event is about.
+func (event *GHEvent) tag() (string, bool) {
+	if strings.HasPrefix(event.Ref, "refs/tags/") {
+		return strings.TrimPrefix(event.Ref, "refs/tags/"), true
+	}
+	return "", false
+}
+
+func isRegexpPattern(p string) bool {
+	return len(p) >= 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/")
+}
+
+func matchPattern(p, s string) bool {
+	re, err := compilePattern(p)
+	return err == nil && re.MatchString(s)
+}
+
+func checkPattern(p string) error {
+	_, err := compilePattern(p)
+	if err != nil {
+		return fmt.Errorf("bad pattern %q: %v", p, err)
+	}
+	return nil
+}
+
+var patternCache = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+func compilePattern(p string) (*regexp.Regexp, error) {
+	patternCache.Lock()
+	defer patternCache.Unlock()
+	if re, ok := patternCache.m[p]; ok {
+		return re, nil
+	}
+	var re *regexp.Regexp
+	var err error
+	if isRegexpPattern(p) {
+		re, err = regexp.Compile(p[1 : len(p)-1])
+	} else {
+		re, err = globToRegexp(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	patternCache.m[p] = re
+	return re, nil
+}
+
+// Translate a glob into an anchored regular expression.
+//
+// A * matches anything except a slash, ** matches anything including slashes,
+// and **/ matches zero or more directories. A ? matches any character but a slash,
+// [abc] is a character class like in a regexp, and \x matches x literally.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			j := strings.IndexByte(glob[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("missing ]")
+			}
+			class := glob[i : i+j+1]
+			if strings.HasPrefix(class, "[!") {
+				class = "[^" + class[2:]
+			}
+			b.WriteString(class)
+			i += j
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func anyPatternMatches(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if matchPattern(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyEqual(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func anyEqualFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestFilterGithubEvent(t *testing.T) {
+	yes, no := true, false
+	const (
+		push    = `{"ref":"refs/heads/main","sender":{"login":"Alice"},"repository":{"name":"app","full_name":"acme/app"}}`
+		issue   = `{"action":"opened","issue":{"labels":[{"name":"Bug"}]},"repository":{"name":"app","full_name":"acme/app"}}`
+		draftPR = `{"action":"opened","pull_request":{"draft":true,"labels":[{"name":"wip"}],"base":{"ref":"main"}},"repository":{"name":"app","full_name":"acme/app"}}`
+		readyPR = `{"action":"opened","pull_request":{"draft":false,"base":{"ref":"main"}},"repository":{"name":"lib","full_name":"acme/lib"}}`
+	)
+	tests := []struct {
+		name      string
+		rules     []*FilterRule
+		eventType string
+		body      string
+		announce  bool
+		rule      int
+	}{
+		{"no rules", nil, "push", push, true, -1},
+		{"no match", []*FilterRule{{Exclude: true, Events: []string{"issues"}}}, "push", push, true, -1},
+		{"exclude", []*FilterRule{{Exclude: true, Events: []string{"push"}}}, "push", push, false, 0},
+		{"first match wins", []*FilterRule{
+			{Events: []string{"push"}, Branches: []string{"main"}},
+			{Exclude: true, Events: []string{"push"}},
+		}, "push", push, true, 0},
+		{"first match wins, excluded", []*FilterRule{
+			{Exclude: true, Senders: []string{"alice"}},
+			{Events: []string{"push"}},
+		}, "push", push, false, 0},
+		{"every field must match", []*FilterRule{
+			{Exclude: true, Events: []string{"push"}, Branches: []string{"develop"}},
+		}, "push", push, true, -1},
+
+		{"repo name", []*FilterRule{{Exclude: true, Repos: []string{"app"}}}, "push", push, false, 0},
+		{"repo full name", []*FilterRule{{Exclude: true, Repos: []string{"acme/app"}}}, "push", push, false, 0},
+		{"repo full name glob", []*FilterRule{{Exclude: true, Repos: []string{"acme/*"}}}, "push", push, false, 0},
+		{"name doesn't match owner", []*FilterRule{{Exclude: true, Repos: []string{"acme"}}}, "push", push, true, -1},
+		{"full name needs owner", []*FilterRule{{Exclude: true, Repos: []string{"other/app"}}}, "push", push, true, -1},
+
+		{"issue label", []*FilterRule{{Exclude: true, Labels: []string{"bug"}}}, "issues", issue, false, 0},
+		{"pull request label", []*FilterRule{{Exclude: true, Labels: []string{"WIP"}}}, "pull_request", draftPR, false, 0},
+		{"label missing", []*FilterRule{{Exclude: true, Labels: []string{"bug"}}}, "pull_request", draftPR, true, -1},
+		{"no labels on a push", []*FilterRule{{Exclude: true, Labels: []string{"bug"}}}, "push", push, true, -1},
+
+		{"draft", []*FilterRule{{Exclude: true, Draft: &yes}}, "pull_request", draftPR, false, 0},
+		{"not draft", []*FilterRule{{Exclude: true, Draft: &yes}}, "pull_request", readyPR, true, -1},
+		{"ready", []*FilterRule{{Exclude: true, Draft: &no}}, "pull_request", readyPR, false, 0},
+		{"draft needs a pull request", []*FilterRule{{Exclude: true, Draft: &no}}, "push", push, true, -1},
+	}
+	for _, tt := range tests {
+		announce, rule := FilterGithubEvent(tt.eventType, parseEvent(t, tt.body), tt.rules)
+		if announce != tt.announce || rule != tt.rule {
+			t.Errorf("%s: got %v, rule %d; want %v, rule %d", tt.name, announce, rule, tt.announce, tt.rule)
+		}
+	}
+}
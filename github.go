@@ -1,30 +1,116 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	urlpkg "net/url"
 	"regexp"
-	"sort"
 	"strings"
 	"time"
 )
 
-// TODO: make sure we don't panic on a nil field
-
 type EventFormatterOptions struct {
+	// Comma-separated patterns of branches and tags to announce.
+	// Branches apply to pushes and to the base branch of pull requests.
+	// Patterns are globs, where ** matches across slashes,
+	// or regular expressions between slashes.
+	// Patterns beginning with ! exclude matching names.
 	Branches string
+	Tags     string
+
+	// Comma-separated patterns of files.
+	// If set, only pushes which touch a matching file are announced,
+	// and only the commits which touched them are shown.
+	// Patterns ending in a slash match everything in a directory.
+	Paths string
+
+	// The number of commits to show for each push (default 3, or -1 for none),
+	// and whether to show the newest commits instead of the oldest.
+	MaxCommits    int
+	NewestCommits bool
+
+	// Commits authored more than OldCommitDays days before they're announced
+	// are marked with their age, like "authored 3 weeks ago",
+	// so that rebased and cherry-picked commits stand out (default 0, for never).
+	OldCommitDays int
+
+	// How to show repositories: by "name" (the default), or by "full" name, like owner/repo,
+	// which tells repositories apart in organization-wide hooks.
+	// RepoAliases gives names to show for particular repositories, by full name.
+	RepoNames   string
+	RepoAliases map[string]string
+
+	// Comma-separated markers which keep commits from being announced,
+	// like "[skip irc]" anywhere in the message or a "Notify: no" trailer
+	// (see stripSkipMarkers).
+	// Pushes of nothing but skipped commits aren't announced,
+	// and the markers are taken out of any commits that are shown.
+	// The default is "[skip irc],[irc skip],[no notify],Notify: no"; "none" turns this off.
+	SkipMarkers string
+
+	// The most characters of a comment to show (default 100, or -1 for no limit).
+	// See summarizeComment.
+	CommentWidth int
+
+	// Edits made within EditWindow of posting a comment, issue or pull request
+	// aren't announced, like "30m" (default "10m", or "0" to announce every edit).
+	// See lateEdit.
+	EditWindow string
+
+	// Comma-separated patterns of deployment environments to announce,
+	// like Branches.
+	Environments string
+	IssueActions string // issue actions to announce besides opened and closed
+
+	// What to do with pushes, pull requests, issues and comments sent by bots
+	// (logins ending in [bot]): "ignore" them (the default), "summarize" them,
+	// or "show" them like any other event. Other events, like deployments
+	// and security alerts, are always shown; see botEvents.
+	// Summarized pushes are announced without any commit details.
+	BotMode string
+	// Comma-separated logins whose events are always ignored,
+	// or whose pushes are always summarized.
+	IgnoreSenders    string
+	SummarizeSenders string
+
+	// The language to write messages in: "en" (the default), "de" or "ja".
+	// Regional locales like "de-AT" use their language's catalog.
+	// Custom Templates should be written in the same language,
+	// since they get its plural rules; see catalog.
+	Locale string
+
+	// Templates to use instead of the default ones, by name.
+	// See defaultTemplates for the names and the defaults.
+	Templates map[string]string
+
+	// How to mark up messages: "irc" (the default), "plain", "markdown", "html" or "json".
+	// NoColors is the same as "plain".
+	Format string
+
+	// The colors of IRC messages: a theme from ircThemes ("default", "dark",
+	// "high-contrast" or "bold-only"), and styles for particular elements
+	// which override the theme, like {"repo": "bold pink", "url": "#3366ff"}.
+	Theme  string
+	Colors map[string]string
+
+	// NoHighlights puts a zero-width space in names,
+	// so that they don't highlight anyone in the channel by accident.
+	// HighlightAuthors announces pull request reviews and failed CI runs,
+	// and highlights the authors of pull requests when their pull request is reviewed
+	// or fails CI, if they're listed in Config.Nicks. Workflow runs don't say who opened
+	// the pull request, so for CI the author is only known if the pull request was opened
+	// or updated since the bot started; see pullRequestAuthors.
+	NoHighlights     bool
+	HighlightAuthors bool
+
 	NoColors bool
 	LongURL  bool
 }
 
 type GHEvent struct {
-	Type       string
-	Sender     GHSender
-	Repository GHRepository
-	//Organization
+	Type         string
+	Sender       GHSender
+	Repository   GHRepository
+	Organization *GHSender
 
 	// Push event
 	// https://developer.github.com/v3/activity/events/types/#pushevent
@@ -39,8 +125,12 @@ type GHEvent struct {
 	Commits []GHCommit
 	Pusher  GHPusher
 
-	// Pull request event
+	// Create & delete events; Ref is the name of the branch or tag
+	RefType string `json:"ref_type"`
+
+	// Pull request event & Pull request review event
 	PullRequest *GHPullRequest `json:"pull_request"`
+	Review      *GHReview
 
 	// Issues event & Issue comment event
 	// https://developer.github.com/v3/activity/events/types/#issuesevent
@@ -49,15 +139,59 @@ type GHEvent struct {
 	Issue   *GHIssue
 	Comment *GHComment
 
+	// Issues event (triage actions), milestone event, label event & project item event
+	Label          *GHLabel
+	Assignee       *GHSender
+	Milestone      *GHMilestone
+	ProjectsV2Item *GHProjectsV2Item `json:"projects_v2_item"`
+
+	// Discussion event & Discussion comment event
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#discussion
+	Discussion *GHDiscussion
+	Answer     *GHComment
+
+	// Deployment event & Deployment status event
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#deployment
+	Deployment       *GHDeployment
+	DeploymentStatus *GHDeploymentStatus `json:"deployment_status"`
+
+	// Member, repository, public, branch protection rule & team add events
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#member
+	Member  *GHSender
+	Changes *GHChanges
+	Rule    *GHBranchProtectionRule
+	Team    *GHTeam
+
 	// Gollum event
 	Pages []GHPage
 
-	// TODO: star
+	// Dependabot alert, code scanning alert & secret scanning alert events
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#dependabot_alert
+	Alert *GHAlert
+
+	// Repository advisory event
+	RepositoryAdvisory *GHSecurityAdvisory `json:"repository_advisory"`
+
+	// Sponsorship event
+	Sponsorship *GHSponsorship
+
+	// Workflow run event
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#workflow_run
+	WorkflowRun *GHWorkflowRun `json:"workflow_run"`
+
+	// Organization, membership & installation events
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#organization
+	Membership   *GHMembership
+	Invitation   *GHInvitation
+	Installation *GHInstallation
+	Repositories []GHRepository
+
 	// TODO: ping
 }
 
 type GHSender struct {
 	Login string
+	Type  string // "User", "Bot", or "Organization"
 	// ...
 }
 
@@ -67,6 +201,9 @@ type GHRepository struct {
 	Private  bool
 	Owner    GHOwner
 	URL      string
+	HtmlUrl  string `json:"html_url"`
+
+	StargazersCount int `json:"stargazers_count"`
 	// ...
 }
 
@@ -76,18 +213,25 @@ type GHOwner struct {
 }
 
 type GHCommit struct {
-	SHA      string
-	Message  string
-	Author   GHAuthor
-	URL      string
-	Distinct bool
+	SHA       string
+	Message   string
+	Author    GHAuthor
+	Committer GHAuthor
+	Timestamp string
+	URL       string
+	Distinct  bool
+
+	Added    []string
+	Modified []string
+	Removed  []string
 
 	ID string // XXX ???
 }
 
 type GHAuthor struct {
-	Name  string
-	Email string
+	Name     string
+	Email    string
+	Username string
 }
 
 type GHPusher struct {
@@ -97,28 +241,204 @@ type GHPusher struct {
 type GHPullRequest struct {
 	Number  int
 	Title   string
+	Body    string
 	HtmlUrl string `json:"html_url"`
 	Head    GHPRBranch
 	Base    GHPRBranch
+	Draft   bool
+	Labels  []GHLabel
+	User    GHSender
+	Commits int
+	Merged  bool
+
+	MergeCommitSHA string `json:"merge_commit_sha"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+type GHReview struct {
+	State   string // "approved", "changes_requested", or "commented"
+	Body    string
+	HtmlUrl string `json:"html_url"`
 }
 
 type GHPRBranch struct {
 	Label string
 	Ref   string
 	SHA   string
+	Repo  *GHRepository
 }
 
 type GHIssue struct {
+	Number    int
+	Title     string
+	Body      string
+	HtmlUrl   string `json:"html_url"`
+	Labels    []GHLabel
+	Milestone *GHMilestone
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	// ...
+}
+
+type GHLabel struct {
+	Name  string
+	Color string // hex RGB, without the #
+}
+
+type GHMilestone struct {
 	Number  int
 	Title   string
+	State   string
+	DueOn   string `json:"due_on"`
 	HtmlUrl string `json:"html_url"`
-	// ...
+}
+
+type GHProjectsV2Item struct {
+	ContentType string `json:"content_type"`
 }
 
 type GHComment struct {
-	Body     string
-	CommitID string `json:"commit_id"`
+	Body      string
+	CommitID  string `json:"commit_id"`
+	HtmlUrl   string `json:"html_url"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type GHDiscussion struct {
+	Number   int
+	Title    string
 	HtmlUrl  string `json:"html_url"`
+	Category GHDiscussionCategory
+}
+
+type GHDiscussionCategory struct {
+	Name string
+}
+
+type GHDeployment struct {
+	ID          int64
+	SHA         string
+	Ref         string
+	Task        string
+	Environment string
+	Description string
+	Creator     GHSender
+}
+
+type GHDeploymentStatus struct {
+	State          string
+	Description    string
+	Environment    string
+	EnvironmentUrl string `json:"environment_url"`
+	LogUrl         string `json:"log_url"`
+	TargetUrl      string `json:"target_url"`
+	Creator        GHSender
+}
+
+type GHChanges struct {
+	Repository *GHRepositoryChanges
+	Owner      *GHOwnerChange
+	Permission *GHChange
+
+	// Label edits
+	Name *GHChange
+
+	// Organization renames
+	Login *GHChange
+
+	// Comment, issue & pull request edits
+	Body *GHChange
+
+	// Transferred issues
+	NewRepository *GHRepository `json:"new_repository"`
+	NewIssue      *GHIssue      `json:"new_issue"`
+
+	// Project item edits
+	FieldValue *GHFieldValueChange `json:"field_value"`
+}
+
+type GHChange struct {
+	From string
+	To   string
+}
+
+// The from and to values depend on the type of the field;
+// they may be strings, numbers, or objects like {"name": "Done"}.
+type GHFieldValueChange struct {
+	FieldName     string `json:"field_name"`
+	FieldType     string `json:"field_type"`
+	ProjectNumber int    `json:"project_number"`
+	From          json.RawMessage
+	To            json.RawMessage
+}
+
+type GHRepositoryChanges struct {
+	Name *GHChange
+}
+
+type GHOwnerChange struct {
+	From struct {
+		User         *GHSender
+		Organization *GHSender
+	}
+}
+
+type GHBranchProtectionRule struct {
+	Name string
+}
+
+type GHTeam struct {
+	Name    string
+	Slug    string
+	HtmlUrl string `json:"html_url"`
+}
+
+type GHMembership struct {
+	User  GHSender
+	Role  string // "admin" or "member"
+	State string
+}
+
+type GHInvitation struct {
+	Login string
+	Role  string
+}
+
+type GHInstallation struct {
+	ID                  int64
+	Account             GHSender
+	AppSlug             string `json:"app_slug"`
+	RepositorySelection string `json:"repository_selection"` // "all" or "selected"
+	HtmlUrl             string `json:"html_url"`
+}
+
+type GHWorkflowRun struct {
+	Name       string
+	RunNumber  int    `json:"run_number"`
+	HeadBranch string `json:"head_branch"`
+	HeadSHA    string `json:"head_sha"`
+	Status     string
+	Conclusion string
+	HtmlUrl    string `json:"html_url"`
+	Actor      GHSender
+
+	// Only the number and branches are filled in.
+	PullRequests []GHPullRequest `json:"pull_requests"`
+}
+
+type GHSponsorship struct {
+	Sponsor      GHSender
+	Sponsorable  GHSender
+	Tier         GHSponsorshipTier
+	PrivacyLevel string `json:"privacy_level"`
+}
+
+type GHSponsorshipTier struct {
+	Name                  string
+	MonthlyPriceInDollars int  `json:"monthly_price_in_dollars"`
+	IsOneTime             bool `json:"is_one_time"`
 }
 
 type GHPage struct {
@@ -128,6 +448,56 @@ type GHPage struct {
 	HtmlUrl string `json:"html_url"`
 }
 
+type GHAlert struct {
+	Number  int
+	State   string
+	HtmlUrl string `json:"html_url"`
+
+	// Dependabot alerts
+	SecurityAdvisory *GHSecurityAdvisory `json:"security_advisory"`
+	Dependency       *GHDependency
+
+	// Code scanning alerts
+	Rule *GHAlertRule
+	Tool *GHAlertTool
+
+	// Secret scanning alerts
+	SecretType            string `json:"secret_type"`
+	SecretTypeDisplayName string `json:"secret_type_display_name"`
+	Resolution            string
+}
+
+type GHSecurityAdvisory struct {
+	GhsaID   string `json:"ghsa_id"`
+	CveID    string `json:"cve_id"`
+	Summary  string
+	Severity string
+	State    string // repository advisories only
+	HtmlUrl  string `json:"html_url"`
+}
+
+type GHDependency struct {
+	Package      GHPackage
+	ManifestPath string `json:"manifest_path"`
+}
+
+type GHPackage struct {
+	Ecosystem string
+	Name      string
+}
+
+type GHAlertRule struct {
+	ID                    string
+	Name                  string
+	Description           string
+	Severity              string
+	SecuritySeverityLevel string `json:"security_severity_level"`
+}
+
+type GHAlertTool struct {
+	Name string
+}
+
 // Parse a JSON payload containing a github event,
 // and return a GHEvent object.
 func ParseGithubEvent(b []byte) (*GHEvent, error) {
@@ -136,19 +506,30 @@ func ParseGithubEvent(b []byte) (*GHEvent, error) {
 	return event, err
 }
 
-// TODO: FilterGithubEvent
+var defaultFormatterOptions = &EventFormatterOptions{
+	LongURL:  true,
+	NoColors: false,
+}
+
+// Returns cfg, or the default options if cfg is nil.
+// The default options are shared, so that their templates are only set up once.
+func defaultOptions(cfg *EventFormatterOptions) *EventFormatterOptions {
+	if cfg == nil {
+		return defaultFormatterOptions
+	}
+	return cfg
+}
 
 // Format a github event according to the event type,
-// in a manner suitable for transmitting via irc.
+// in a manner suitable for transmitting via irc
+// (or whatever cfg.Format says).
 // May return multiple lines.
 // May return an empty string if the event should be ignored.
 // The cfg parameter can be nil, in which case the default options will be used.
 func FormatGithubEvent(eventType string, event *GHEvent, cfg *EventFormatterOptions) string {
-	if cfg == nil {
-		cfg = &EventFormatterOptions{
-			LongURL:  true,
-			NoColors: false,
-		}
+	cfg = defaultOptions(cfg)
+	if cfg.ignoresSender(eventType, event) {
+		return ""
 	}
 	var msg = ""
 	switch eventType {
@@ -158,108 +539,686 @@ func FormatGithubEvent(eventType string, event *GHEvent, cfg *EventFormatterOpti
 		msg = receive_commit_comment(event, cfg)
 	case "pull_request":
 		msg = receive_pull_request(event, cfg)
+	case "pull_request_review":
+		msg = receive_pull_request_review(event, cfg)
 	case "pull_request_review_comment":
 		msg = receive_pull_request_review_comment(event, cfg)
 	case "issues": // random plural
 		msg = receive_issues(event, cfg)
 	case "issue_comment":
 		msg = receive_issue_comment(event, cfg)
+	case "discussion":
+		msg = receive_discussion(event, cfg)
+	case "discussion_comment":
+		msg = receive_discussion_comment(event, cfg)
+	case "deployment":
+		msg = receive_deployment(event, cfg)
+	case "deployment_status":
+		msg = receive_deployment_status(event, cfg)
+	case "member":
+		msg = receive_member(event, cfg)
+	case "repository":
+		msg = receive_repository(event, cfg)
+	case "public":
+		msg = receive_public(event, cfg)
+	case "branch_protection_rule":
+		msg = receive_branch_protection_rule(event, cfg)
+	case "team_add":
+		msg = receive_team_add(event, cfg)
+	case "star": // GitHub also sends a watch event for each star, which is ignored
+		msg = receive_star(event, cfg)
+	case "sponsorship":
+		msg = receive_sponsorship(event, cfg)
+	case "milestone":
+		msg = receive_milestone(event, cfg)
+	case "label":
+		msg = receive_label(event, cfg)
+	case "projects_v2_item":
+		msg = receive_projects_v2_item(event, cfg)
 	case "gollum":
 		msg = receive_gollum(event, cfg)
+	case "dependabot_alert":
+		msg = receive_dependabot_alert(event, cfg)
+	case "code_scanning_alert":
+		msg = receive_code_scanning_alert(event, cfg)
+	case "secret_scanning_alert":
+		msg = receive_secret_scanning_alert(event, cfg)
+	case "repository_advisory":
+		msg = receive_repository_advisory(event, cfg)
+	case "workflow_run":
+		msg = receive_workflow_run(event, cfg)
+	case "organization":
+		msg = receive_organization(event, cfg)
+	case "membership":
+		msg = receive_membership(event, cfg)
+	case "installation":
+		msg = receive_installation(event, cfg)
 	default:
 		//receive_unknown(eventType, event, cfg)
 	}
 
-	if cfg.NoColors {
-		msg = colorRE.ReplaceAllString(msg, "")
+	return msg
+}
+
+// Format a burst of events for the same repository, as FormatGithubEvent would,
+// except that related events are combined into one message.
+// A merged pull request takes in the push of the merge to the base branch,
+// and the deletion of the head branch.
+func FormatGithubEvents(events []BurstEvent, cfg *EventFormatterOptions) []string {
+	cfg = defaultOptions(cfg)
+	msgs := make([]string, len(events))
+	combined := make([]bool, len(events))
+	for i, e := range events {
+		if e.Type != "pull_request" || !e.Event.merged() {
+			continue
+		}
+		var related []int
+		deleted := false
+		for j, f := range events {
+			if j == i || combined[j] {
+				continue
+			}
+			if mergePushed(e.Event, f.Type, f.Event) {
+				related = append(related, j)
+			} else if headDeleted(e.Event, f.Type, f.Event) {
+				related = append(related, j)
+				deleted = true
+			}
+		}
+		if len(related) == 0 {
+			continue
+		}
+		msgs[i] = receive_merged_pull_request(e.Event, deleted, cfg)
+		if msgs[i] == "" {
+			continue
+		}
+		for _, j := range related {
+			combined[j] = true
+		}
+		combined[i] = true
+	}
+	var out []string
+	for i, e := range events {
+		if !combined[i] {
+			msgs[i] = FormatGithubEvent(e.Type, e.Event, cfg)
+		}
+		if msgs[i] != "" {
+			out = append(out, msgs[i])
+		}
 	}
+	return out
+}
 
-	return msg
+// Reports whether an event is the push of a merged pull request to its base branch.
+func mergePushed(pr *GHEvent, eventType string, event *GHEvent) bool {
+	p := pr.PullRequest
+	if eventType != "push" || event.deleted() || event.Ref != "refs/heads/"+p.Base.Ref {
+		return false
+	}
+	return p.MergeCommitSHA == "" || event.After == p.MergeCommitSHA
+}
+
+// Reports whether an event deletes the head branch of a merged pull request.
+func headDeleted(pr *GHEvent, eventType string, event *GHEvent) bool {
+	p := pr.PullRequest
+	if p.Head.Repo != nil && p.Head.Repo.FullName != event.Repository.FullName {
+		return false
+	}
+	return event.deletesBranch(eventType, p.Head.Ref)
 }
 
-func getDistinctCommits(event *GHEvent) []*GHCommit {
-	var distinctCommits []*GHCommit
+// Format a digest of what happened in a repository
+// over the last period ("daily" or "weekly").
+func FormatDigest(repo string, tally *DigestTally, period string, cfg *EventFormatterOptions) string {
+	cfg = defaultOptions(cfg)
+	if alias, ok := cfg.RepoAliases[repo]; ok {
+		repo = alias
+	}
+	m := &Message{
+		Type:   "digest",
+		Event:  &GHEvent{},
+		Repo:   repo,
+		Action: period,
+		Digest: tally,
+	}
+	return cfg.render("digest", m)
+}
+
+// Format a summary of several stars which a repository received
+// within the given window of time.
+// The event should be the last star event received.
+func FormatStarBurst(event *GHEvent, stars int, window time.Duration, cfg *EventFormatterOptions) string {
+	cfg = defaultOptions(cfg)
+	summary_url := cfg.maybe_shorten(irc_star_summary_url(event))
+	m := cfg.newMessage("star", event, summary_url)
+	m.Count = stars
+	m.Window = window
+	if stars == 1 {
+		return cfg.render("star", m)
+	}
+	return cfg.render("star.burst", m)
+}
+
+// Reports whether an event adds (+1) or removes (-1) a star.
+func starDelta(eventType string, event *GHEvent) int {
+	switch {
+	case eventType == "star" && event.Action == "created":
+		return +1
+	case eventType == "star" && event.Action == "deleted":
+		return -1
+	}
+	return 0
+}
+
+// The new commits in a push, with any skip markers taken out of their messages.
+// Also reports whether every one of them was marked to be skipped.
+// The cfg parameter can be nil, in which case the default markers are used.
+func getDistinctCommits(event *GHEvent, cfg *EventFormatterOptions) (commits []*GHCommit, skipped bool) {
+	markers := cfg.skipMarkers()
+	skipped = true
 	for i := range event.Commits {
 		commit := &event.Commits[i]
-		if commit.Distinct && strings.TrimSpace(commit.Message) != "" {
-			distinctCommits = append(distinctCommits, commit)
+		if !commit.Distinct || strings.TrimSpace(commit.Message) == "" {
+			continue
+		}
+		if message, ok := stripSkipMarkers(commit.Message, markers); ok {
+			c := *commit
+			c.Message = message
+			commit = &c
+		} else {
+			skipped = false
+		}
+		commits = append(commits, commit)
+	}
+	return commits, skipped && len(commits) > 0
+}
+
+var defaultSkipMarkers = []string{"[skip irc]", "[irc skip]", "[no notify]", "Notify: no"}
+
+func (data *EventFormatterOptions) skipMarkers() []string {
+	if data == nil || data.SkipMarkers == "" {
+		return defaultSkipMarkers
+	}
+	if data.SkipMarkers == "none" {
+		return nil
+	}
+	var markers []string
+	for _, m := range strings.Split(data.SkipMarkers, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			markers = append(markers, m)
 		}
 	}
-	return distinctCommits
+	return markers
+}
+
+// Take skip markers out of a commit message, ignoring case.
+// Reports whether there were any.
+// Markers in square brackets can be anywhere in the message;
+// others, like trailers, have to be a line of their own.
+func stripSkipMarkers(message string, markers []string) (string, bool) {
+	found := false
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		for _, m := range markers {
+			if !strings.HasPrefix(m, "[") {
+				if strings.EqualFold(strings.TrimSpace(line), m) {
+					line, found = "", true
+				}
+				continue
+			}
+			for i := 0; i+len(m) <= len(line); i++ {
+				if strings.EqualFold(line[i:i+len(m)], m) {
+					line, found = line[:i]+line[i+len(m):], true
+					i--
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+	if !found {
+		return message, false
+	}
+	// Don't leave gaps where the markers were.
+	var kept []string
+	for _, line := range lines {
+		line = strings.TrimRight(strings.Replace(line, "  ", " ", -1), " \t\r")
+		if len(kept) == 0 {
+			line = strings.TrimLeft(line, " ")
+		}
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n"), true
 }
 
 func receive_push(event *GHEvent, cfg *EventFormatterOptions) string {
 	if !cfg.branchNameMatches(event) {
 		return ""
 	}
+	if cfg.Paths != "" {
+		event = cfg.filterPaths(event)
+		if event == nil {
+			return ""
+		}
+	}
 
-	distinct_commits := getDistinctCommits(event)
-	summary_message := irc_push_summary_message(event)
+	distinct_commits, skipped := getDistinctCommits(event, cfg)
+	if skipped {
+		return ""
+	}
 	summary_url := cfg.maybe_shorten(irc_push_summary_url(event))
 
+	m := cfg.newMessage("push", event, summary_url)
+	if event.Pusher.Name != "" {
+		m.Actor = event.Pusher.Name
+	} else {
+		m.Actor = "somebody"
+	}
+	m.Commits = distinct_commits
+
 	var messages []string
-	messages = append(messages, fmt.Sprintf("%s: %s", summary_message, fmt_url(summary_url)))
-	for i, commit := range distinct_commits {
-		if i >= 3 {
-			break
+	messages = append(messages, cfg.execute("push", m))
+	if cfg.summarizesSender(event) {
+		return cfg.finish(m, messages[0])
+	}
+
+	shown_commits := distinct_commits
+	max := cfg.maxCommits()
+	if len(shown_commits) > max {
+		if cfg.NewestCommits {
+			shown_commits = shown_commits[len(shown_commits)-max:]
+		} else {
+			shown_commits = shown_commits[:max]
+		}
+	}
+	for _, commit := range shown_commits {
+		c := *m
+		c.Commit = commit
+		if cfg.Paths != "" {
+			c.Files = len(commit.files())
 		}
-		messages = append(messages, irc_format_commit_message(event, commit))
+		messages = append(messages, cfg.execute("push.commit", &c))
+	}
+	if hidden := len(distinct_commits) - len(shown_commits); hidden > 0 && len(shown_commits) > 0 {
+		more := *m
+		more.Count = hidden
+		more.Newest = cfg.NewestCommits
+		more.URL = cfg.maybe_shorten(event.Compare)
+		messages = append(messages, cfg.execute("push.more", &more))
 	}
 
-	return strings.Join(messages, "\n")
+	return cfg.finish(m, messages...)
 }
 
 func receive_commit_comment(event *GHEvent, cfg *EventFormatterOptions) string {
-	summary_message := irc_commit_comment_summary_message(event)
+	if event.Comment == nil {
+		return ""
+	}
 	summary_url := cfg.maybe_shorten(irc_commit_comment_summary_url(event))
-	return fmt.Sprintf("%s %s", summary_message, fmt_url(summary_url))
+	return cfg.render("commit_comment", cfg.newMessage("commit_comment", event, summary_url))
 }
 
 func receive_pull_request(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.PullRequest == nil {
+		return ""
+	}
+	if !cfg.branchNameMatches(event) {
+		return ""
+	}
 	action := event.Action
 	if strings.Contains(action, "open") || strings.Contains(action, "close") {
-		summary_message := irc_pull_request_summary_message(event)
 		summary_url := cfg.maybe_shorten(irc_pull_request_summary_url(event))
-		return fmt.Sprintf("%s %s", summary_message, fmt_url(summary_url))
+		return cfg.render("pull_request", cfg.newMessage("pull_request", event, summary_url))
+	}
+	if pr := event.PullRequest; event.bodyEdited() && cfg.lateEdit(pr.CreatedAt, pr.UpdatedAt) {
+		summary_url := cfg.maybe_shorten(irc_pull_request_summary_url(event))
+		return cfg.render("pull_request", cfg.newEditMessage("pull_request", event, summary_url, pr.Body))
 	}
 	return ""
 }
 
+// A merged pull request, along with the push and branch deletion that came with it.
+func receive_merged_pull_request(event *GHEvent, deleted bool, cfg *EventFormatterOptions) string {
+	if cfg.ignoresSender("pull_request", event) || !cfg.branchNameMatches(event) {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_pull_request_summary_url(event))
+	m := cfg.newMessage("pull_request", event, summary_url)
+	m.Count = event.PullRequest.Commits
+	m.Deleted = deleted
+	return cfg.render("pull_request.merged", m)
+}
+
+// Reviews are only announced if HighlightAuthors is set.
+func receive_pull_request_review(event *GHEvent, cfg *EventFormatterOptions) string {
+	if !cfg.HighlightAuthors {
+		return ""
+	}
+	if event.Review == nil || event.PullRequest == nil || event.Action != "submitted" {
+		return ""
+	}
+	if !cfg.branchNameMatches(event) {
+		return ""
+	}
+	// Reviews that only leave comments on the code are announced
+	// through their pull_request_review_comment events.
+	if event.Review.State == "commented" && strings.TrimSpace(event.Review.Body) == "" {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_pull_request_review_summary_url(event))
+	m := cfg.newMessage("pull_request_review", event, summary_url)
+	m.Highlight = cfg.highlight(event.PullRequest.User.Login, event.Sender.Login)
+	return cfg.render("pull_request_review", m)
+}
+
 func receive_pull_request_review_comment(event *GHEvent, cfg *EventFormatterOptions) string {
-	summary_message := irc_pull_request_review_comment_summary_message(event)
+	if event.Comment == nil || event.PullRequest == nil {
+		return ""
+	}
+	if !cfg.branchNameMatches(event) {
+		return ""
+	}
+	if event.Action == "edited" && !cfg.lateEdit(event.Comment.CreatedAt, event.Comment.UpdatedAt) {
+		return ""
+	}
 	summary_url := cfg.maybe_shorten(irc_pull_request_review_comment_summary_url(event))
-	return fmt.Sprintf("%s %s", summary_message, fmt_url(summary_url))
+	return cfg.render("pull_request_review_comment", cfg.newEditMessage("pull_request_review_comment", event, summary_url, event.Comment.Body))
 }
 
 func receive_issues(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Issue == nil {
+		return ""
+	}
 	action := event.Action
 	if strings.Contains(action, "open") || strings.Contains(action, "close") {
-		summary_message := irc_issue_summary_message(event)
 		summary_url := cfg.maybe_shorten(irc_issue_summary_url(event))
-		return fmt.Sprintf("%s %s", summary_message, fmt_url(summary_url))
+		return cfg.render("issues", cfg.newMessage("issues", event, summary_url))
+	}
+	if issue := event.Issue; event.bodyEdited() {
+		if !cfg.lateEdit(issue.CreatedAt, issue.UpdatedAt) {
+			return ""
+		}
+		summary_url := cfg.maybe_shorten(irc_issue_summary_url(event))
+		return cfg.render("issues", cfg.newEditMessage("issues", event, summary_url, issue.Body))
+	}
+	if cfg.issueActionMatches(event) {
+		summary_url := cfg.maybe_shorten(irc_issue_summary_url(event))
+		return cfg.render("issues.triage", cfg.newMessage("issues", event, summary_url))
 	}
 	return ""
 }
+
 func receive_issue_comment(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Issue == nil || event.Comment == nil {
+		return ""
+	}
 	action := event.Action
-	if action == "edited" {
-		// TODO: only ignore edits for a short window after the comment was created?
-		// so if someone immediately fixes a typo we won't get a duplicate notification,
-		// but if they go back a week later and change something we will
+	if action == "edited" && !cfg.lateEdit(event.Comment.CreatedAt, event.Comment.UpdatedAt) {
 		return ""
 	}
-	summary_message := irc_issue_comment_summary_message(event)
 	summary_url := cfg.maybe_shorten(irc_issue_comment_summary_url(event))
-	return fmt.Sprintf("%s %s", summary_message, fmt_url(summary_url))
+	return cfg.render("issue_comment", cfg.newEditMessage("issue_comment", event, summary_url, event.Comment.Body))
+}
+
+func receive_discussion(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Discussion == nil {
+		return ""
+	}
+	action := event.Action
+	if action == "created" || action == "answered" || strings.Contains(action, "open") || strings.Contains(action, "close") {
+		summary_url := cfg.maybe_shorten(irc_discussion_summary_url(event))
+		return cfg.render("discussion", cfg.newMessage("discussion", event, summary_url))
+	}
+	return ""
+}
+
+func receive_discussion_comment(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Discussion == nil || event.Comment == nil {
+		return ""
+	}
+	action := event.Action
+	if action == "edited" && !cfg.lateEdit(event.Comment.CreatedAt, event.Comment.UpdatedAt) {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_discussion_comment_summary_url(event))
+	return cfg.render("discussion_comment", cfg.newEditMessage("discussion_comment", event, summary_url, event.Comment.Body))
+}
+
+func receive_deployment(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Deployment == nil {
+		return ""
+	}
+	if !cfg.environmentMatches(event) {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_deployment_summary_url(event))
+	m := cfg.newMessage("deployment", event, summary_url)
+	if event.Deployment.Creator.Login != "" {
+		m.Actor = event.Deployment.Creator.Login
+	}
+	return cfg.render("deployment", m)
+}
+
+func receive_deployment_status(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Deployment == nil || event.DeploymentStatus == nil {
+		return ""
+	}
+	if !cfg.environmentMatches(event) {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_deployment_status_summary_url(event))
+	return cfg.render("deployment_status", cfg.newMessage("deployment_status", event, summary_url))
+}
+
+func receive_member(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Member == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_member_summary_url(event))
+	return cfg.render("member", cfg.newMessage("member", event, summary_url))
+}
+
+func receive_repository(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Action == "edited" {
+		// descriptions and topics are not very interesting
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(event.Repository.web_url())
+	return cfg.render("repository", cfg.newMessage("repository", event, summary_url))
+}
+
+func receive_public(event *GHEvent, cfg *EventFormatterOptions) string {
+	summary_url := cfg.maybe_shorten(event.Repository.web_url())
+	return cfg.render("public", cfg.newMessage("public", event, summary_url))
+}
+
+func receive_branch_protection_rule(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Rule == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_branch_protection_rule_summary_url(event))
+	return cfg.render("branch_protection_rule", cfg.newMessage("branch_protection_rule", event, summary_url))
+}
+
+func receive_team_add(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Team == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_member_summary_url(event))
+	return cfg.render("team_add", cfg.newMessage("team_add", event, summary_url))
+}
+
+func receive_star(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Action != "created" {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_star_summary_url(event))
+	return cfg.render("star", cfg.newMessage("star", event, summary_url))
+}
+
+func receive_sponsorship(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Sponsorship == nil {
+		return ""
+	}
+	action := event.Action
+	if action == "created" || action == "cancelled" || action == "tier_changed" {
+		summary_url := cfg.maybe_shorten(irc_sponsorship_summary_url(event))
+		m := cfg.newMessage("sponsorship", event, summary_url)
+		m.Repo = event.Sponsorship.Sponsorable.Login
+		m.Actor = event.Sponsorship.Sponsor.Login
+		return cfg.render("sponsorship", m)
+	}
+	return ""
+}
+
+func receive_milestone(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Milestone == nil || event.Action == "edited" {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_milestone_summary_url(event))
+	return cfg.render("milestone", cfg.newMessage("milestone", event, summary_url))
+}
+
+func receive_label(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Label == nil {
+		return ""
+	}
+	if event.Action == "edited" && (event.Changes == nil || event.Changes.Name == nil) {
+		// only renames are interesting
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_label_summary_url(event))
+	return cfg.render("label", cfg.newMessage("label", event, summary_url))
+}
+
+func receive_projects_v2_item(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.ProjectsV2Item == nil {
+		return ""
+	}
+	switch event.Action {
+	case "created", "deleted", "archived", "restored", "converted":
+	case "edited":
+		if event.Changes == nil || event.Changes.FieldValue == nil {
+			return ""
+		}
+	default:
+		// reordered
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_projects_v2_item_summary_url(event))
+	m := cfg.newMessage("projects_v2_item", event, summary_url)
+	if event.Organization != nil {
+		m.Repo = event.Organization.Login
+	}
+	return cfg.render("projects_v2_item", m)
+}
+
+func receive_organization(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Organization == nil {
+		return ""
+	}
+	switch event.Action {
+	case "member_added", "member_removed":
+		if event.Membership == nil {
+			return ""
+		}
+	case "member_invited":
+		if event.Invitation == nil {
+			return ""
+		}
+	}
+	summary_url := cfg.maybe_shorten(irc_organization_summary_url(event))
+	return cfg.render("organization", cfg.newMessage("organization", event, summary_url))
+}
+
+func receive_membership(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Organization == nil || event.Member == nil || event.Team == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_membership_summary_url(event))
+	return cfg.render("membership", cfg.newMessage("membership", event, summary_url))
+}
+
+func receive_installation(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Installation == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_installation_summary_url(event))
+	m := cfg.newMessage("installation", event, summary_url)
+	if event.Organization == nil {
+		m.Repo = event.Installation.Account.Login
+	}
+	return cfg.render("installation", m)
 }
 
 func receive_gollum(event *GHEvent, cfg *EventFormatterOptions) string {
-	summary_message := irc_gollum_summary_message(event)
+	if len(event.Pages) == 0 {
+		return ""
+	}
 	summary_url := irc_gollum_summary_url(event) // not shortened
-	return fmt.Sprintf("%s %s", summary_message, fmt_url(summary_url))
+	return cfg.render("gollum", cfg.newMessage("gollum", event, summary_url))
+}
+
+func receive_dependabot_alert(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Alert == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_alert_summary_url(event))
+	return cfg.render("dependabot_alert", cfg.newMessage("dependabot_alert", event, summary_url))
+}
+
+func receive_code_scanning_alert(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Alert == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_alert_summary_url(event))
+	return cfg.render("code_scanning_alert", cfg.newMessage("code_scanning_alert", event, summary_url))
+}
+
+func receive_secret_scanning_alert(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.Alert == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_alert_summary_url(event))
+	return cfg.render("secret_scanning_alert", cfg.newMessage("secret_scanning_alert", event, summary_url))
+}
+
+func receive_repository_advisory(event *GHEvent, cfg *EventFormatterOptions) string {
+	if event.RepositoryAdvisory == nil {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_repository_advisory_summary_url(event))
+	return cfg.render("repository_advisory", cfg.newMessage("repository_advisory", event, summary_url))
+}
+
+// Only failed runs are announced, and only if HighlightAuthors is set.
+func receive_workflow_run(event *GHEvent, cfg *EventFormatterOptions) string {
+	run := event.WorkflowRun
+	if !cfg.HighlightAuthors || run == nil || event.Action != "completed" {
+		return ""
+	}
+	if run.Conclusion != "failure" && run.Conclusion != "timed_out" {
+		return ""
+	}
+	if !cfg.branchNameMatches(event) {
+		return ""
+	}
+	summary_url := cfg.maybe_shorten(irc_workflow_run_summary_url(event))
+	m := cfg.newMessage("workflow_run", event, summary_url)
+	m.Actor = run.Actor.Login
+	// The payload doesn't say who opened the pull request,
+	// so it's only known if the bot saw it opened or updated.
+	for _, pr := range run.PullRequests {
+		if author := pullRequestAuthors.author(event.Repository.FullName, pr.Number); author != "" {
+			m.Highlight = cfg.highlight(author, "")
+			break
+		}
+	}
+	return cfg.render("workflow_run", m)
 }
 
-var colorRE = regexp.MustCompile(`\002|\017|\026|\037|\003\d{0,2}(?:,\d{1,2})?`)
+var colorRE = regexp.MustCompile(`\002|\017|\026|\035|\037|\003\d{0,2}(?:,\d{1,2})?|\004(?:[[:xdigit:]]{6}(?:,[[:xdigit:]]{6})?)?`)
 
 /*
 func irc_realname() string {
@@ -283,98 +1242,123 @@ func repo_owner() string {
 }
 */
 
+// The name to show for the repository an event happened in,
+// or for the organization, if the event isn't about a repository.
+func (data *EventFormatterOptions) repoName(event *GHEvent) string {
+	repo := event.Repository
+	if repo.Name == "" && repo.FullName == "" && event.Organization != nil {
+		return event.Organization.Login
+	}
+	if alias, ok := data.RepoAliases[repo.FullName]; ok {
+		return alias
+	}
+	if data.RepoNames == "full" && repo.FullName != "" {
+		return repo.FullName
+	}
+	return repo.Name
+}
+
 func (data *EventFormatterOptions) maybe_shorten(summary_url string) string {
-	if data.LongURL {
+	if data.LongURL || summary_url == "" {
 		return summary_url
 	} else {
 		return shorten_url(summary_url)
 	}
 }
 
-/// IRC message formatting.  For reference:
-/// \002 bold   \003 color   \017 reset  \026 italic/reverse  \037 underline
-/// 0 white           1 black         2 dark blue         3 dark green
-/// 4 dark red        5 brownish      6 dark purple       7 orange
-/// 8 yellow          9 light green   10 dark teal        11 light teal
-/// 12 light blue     13 light purple 14 dark gray        15 light gray
-
-func fmt_url(s string) string    { return "\00302\037" + s + "\017" }
-func fmt_repo(s string) string   { return "\00313" + s + "\017" }
-func fmt_name(s string) string   { return "\00315" + s + "\017" }
-func fmt_branch(s string) string { return "\00306" + s + "\017" }
-func fmt_tag(s string) string    { return "\00306" + s + "\017" }
-func fmt_hash(s string) string   { return "\00314" + s + "\017" }
-
-func plural(n int, singular, plural string) string {
-	if n == 1 {
-		return singular
-	} else {
-		return plural
+// Severities are styled by how loudly they ought to shout.
+func severityStyle(s string) string {
+	switch strings.ToLower(s) {
+	case "critical":
+		return "critical"
+	case "high", "error":
+		return "high"
+	case "medium", "moderate", "warning":
+		return "medium"
+	case "low", "note":
+		return "low"
+	default:
+		return ""
 	}
 }
 
-func irc_push_summary_message(event *GHEvent) string {
-	var b bytes.Buffer
-
-	pusher_name := "somebody"
-	if event.Pusher.Name != "" {
-		pusher_name = event.Pusher.Name
+func stateStyle(s string) string {
+	switch s {
+	case "success":
+		return "success"
+	case "failure", "error":
+		return "failure"
+	case "pending", "queued", "in_progress":
+		return "pending"
+	case "inactive":
+		return "inactive"
+	default:
+		return ""
 	}
+}
 
-	repo_name := event.Repository.Name
-
-	fmt.Fprintf(&b, "[%v] %v", fmt_repo(repo_name), fmt_name(pusher_name))
-
-	distinct_commits := getDistinctCommits(event)
-
-	tag := strings.HasPrefix(event.Ref, "refs/tags/")
-	base_ref := event.BaseRef
-	before_sha := event.Before[0:7]
-	after_sha := event.After[0:7]
-	tag_name := event.ref_name()
-	branch_name := event.ref_name()
-	base_ref_name := event.base_ref_name()
-
-	if event.created() {
-		if tag {
-			fmt.Fprintf(&b, " tagged %s at", fmt_tag(tag_name))
-			if base_ref != "" {
-				fmt.Fprintf(&b, " %v", fmt_branch(base_ref_name))
-			} else {
-				fmt.Fprintf(&b, " %v", fmt_hash(after_sha))
-			}
-		} else {
-			fmt.Fprintf(&b, " created %s", fmt_branch(branch_name))
-
-			if base_ref != "" {
-				fmt.Fprintf(&b, " from %s", fmt_branch(base_ref_name))
-			} else if len(distinct_commits) == 0 {
-				fmt.Fprintf(&b, " at %s", fmt_hash(after_sha))
-			}
-
-			num := len(distinct_commits)
-			fmt.Fprintf(&b, " (+\002%d\017 new commit%s)", num, plural(num, "", "s"))
-		}
+// Formats a number with thousands separators, like 1,204.
+func fmt_count(n int) string {
+	if n < 0 {
+		return "-" + fmt_count(-n)
+	}
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
 
-	} else if event.deleted() {
-		fmt.Fprintf(&b, " \00304deleted\017 %s at %s", fmt_branch(branch_name), fmt_hash(before_sha))
+// Formats a duration as a phrase like "hour" or "10 minutes".
+func fmt_window(d time.Duration) string {
+	switch {
+	case d == time.Hour:
+		return "hour"
+	case d == time.Minute:
+		return "minute"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
 
-	} else if event.forced() {
-		fmt.Fprintf(&b, " \00304force-pushed\017 %s from %s to %s", fmt_branch(branch_name), fmt_hash(before_sha), fmt_hash(after_sha))
+// The standard mIRC palette, as RGB.
+var ircPalette = [16][3]int{
+	{255, 255, 255}, {0, 0, 0}, {0, 0, 127}, {0, 147, 0},
+	{255, 0, 0}, {127, 0, 0}, {156, 0, 156}, {252, 127, 0},
+	{255, 255, 0}, {0, 252, 0}, {0, 147, 147}, {0, 255, 255},
+	{0, 0, 252}, {255, 0, 255}, {127, 127, 127}, {210, 210, 210},
+}
 
-	} else if len(event.Commits) > 0 && len(distinct_commits) == 0 {
-		if base_ref != "" {
-			fmt.Fprintf(&b, " merged %s into %s", fmt_branch(base_ref_name), fmt_branch(branch_name))
-		} else {
-			fmt.Fprintf(&b, " fast-forwarded %s from %s to %s", fmt_branch(branch_name), fmt_hash(before_sha), fmt_hash(after_sha))
+// Finds the mIRC color closest to a hex RGB color like "d73a4a".
+func nearestColor(hex string) (int, bool) {
+	var r, g, b int
+	if len(hex) != 6 {
+		return 0, false
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return 0, false
+	}
+	best, bestDist := 0, -1
+	for i, p := range ircPalette {
+		dr, dg, db := r-p[0], g-p[1], b-p[2]
+		dist := dr*dr + dg*dg + db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
 		}
+	}
+	return best, true
+}
 
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
 	} else {
-		num := len(distinct_commits)
-		fmt.Fprintf(&b, " pushed \002%d\017 new commit%s to %s", num, plural(num, "", "s"), fmt_branch(branch_name))
+		return plural
 	}
-
-	return b.String()
 }
 
 var allZeroRef = strings.Repeat("0", 40)
@@ -397,114 +1381,68 @@ func (event *GHEvent) base_ref_name() string {
 	return strings.TrimPrefix(event.BaseRef, "refs/heads/")
 }
 
-func firstLineOf(s string) string {
-	newline := strings.Index(s, "\n")
-	if newline >= 0 {
-		s = s[:newline] + "..."
+// The repository's URL on github.com.
+// Not every payload includes html_url, but those that don't
+// use the same URL for url.
+func (repo *GHRepository) web_url() string {
+	if repo.HtmlUrl != "" {
+		return repo.HtmlUrl
 	}
-	return s
-}
-
-func irc_format_commit_message(event *GHEvent, commit *GHCommit) string {
-	short := firstLineOf(commit.Message)
-
-	repo_name := event.Repository.Name
-	branch_name := event.ref_name()
-
-	author := commit.Author.Name
-	sha1 := commit.ID
-
-	return fmt.Sprintf("%v/%v %v %v: %s",
-		fmt_repo(repo_name), fmt_branch(branch_name), fmt_hash(sha1[0:7]), fmt_name(author), short)
+	return repo.URL
 }
 
-func irc_issue_summary_message(event *GHEvent) string {
-	repo := &event.Repository
-	sender := &event.Sender
-	issue := event.Issue
-	return fmt.Sprintf("[%s] %s %s issue #%d: %s", fmt_repo(repo.Name), fmt_name(sender.Login), event.Action, issue.Number, issue.Title)
-}
-
-func irc_issue_comment_summary_message(event *GHEvent) string {
-	repo := &event.Repository
-	sender := &event.Sender
-	issue := event.Issue
-	short := firstLineOf(event.Comment.Body)
-	return fmt.Sprintf("[%s] %s commented on issue #%d: %s", fmt_repo(repo.Name), fmt_name(sender.Login), issue.Number, short)
+// Abbreviates a commit hash to 7 characters.
+// Payloads don't always have the hash we expect, so shorter strings are left alone.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[0:7]
+	}
+	return sha
 }
 
-func irc_commit_comment_summary_message(event *GHEvent) string {
-	repo := &event.Repository
-	sender := &event.Sender
-	comment := event.Comment
-	short := firstLineOf(comment.Body)
-	sha1 := comment.CommitID
-	return fmt.Sprintf("[%s] %s commented on commit %s: %s", fmt_repo(repo.Name), fmt_name(sender.Login), fmt_hash(sha1[0:7]), short)
+func firstLineOf(s string) string {
+	newline := strings.Index(s, "\n")
+	if newline >= 0 {
+		s = strings.TrimSuffix(s[:newline], "\r") + "..."
+	}
+	return s
 }
 
-func irc_pull_request_summary_message(event *GHEvent) string {
-	repo := &event.Repository
-	sender := &event.Sender
-	pull := event.PullRequest
-	base_ref := event.PullRequest.Base.Ref
-	head_ref := event.PullRequest.Head.Ref
-	head_label := head_ref
-	if head_ref == base_ref {
-		head_label = event.PullRequest.Head.Label
+// Turns the value of a project field into something readable.
+func fieldValueString(raw json.RawMessage) string {
+	var v interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return ""
 	}
-
-	return fmt.Sprintf("[%v] %v %v pull request #%v: %v (%v...%v)",
-		fmt_repo(repo.Name), fmt_name(sender.Login), event.Action, pull.Number, pull.Title, fmt_branch(base_ref), fmt_branch(head_label))
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprint(v)
+	case map[string]interface{}:
+		for _, key := range []string{"name", "title"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
 }
 
-func irc_pull_request_review_comment_summary_message(event *GHEvent) string {
-	repo := &event.Repository
-	sender := &event.Sender
-	comment := event.Comment
-	short, _ := partition(comment.Body, "\r\n")
-	if short != comment.Body {
-		short += "..."
+// The environment a deployment event is for.
+// Deployment statuses can override the environment of their deployment.
+func (deployment *GHDeployment) environment(event *GHEvent) string {
+	if event.DeploymentStatus != nil && event.DeploymentStatus.Environment != "" {
+		return event.DeploymentStatus.Environment
 	}
-	sha1 := comment.CommitID
-	//pull_request_number := comment.pull_request_url =~ /\/(\d+)$/
-	pull_request_number := event.PullRequest.Number
-
-	return fmt.Sprintf("[%v] %v commented on pull request #%v %v: %v",
-		fmt_repo(repo.Name), fmt_name(sender.Login), pull_request_number, fmt_hash(sha1[0:7]), short)
+	return deployment.Environment
 }
 
-func irc_gollum_summary_message(event *GHEvent) string {
-	repo := &event.Repository
-	sender := &event.Sender
-	if len(event.Pages) == 1 {
-		summary := event.Pages[0].Summary
-		msg := fmt.Sprintf("[%s] %s %s wiki page %s",
-			repo.Name,
-			sender.Login,
-			event.Pages[0].Action,
-			event.Pages[0].Title)
-		if summary != "" {
-			msg += ": " + summary
-		}
-		return msg
-	} else {
-		var counts = make(map[string]int)
-		for i := range event.Pages {
-			counts[event.Pages[i].Action] += 1
-		}
-
-		var actions []string
-		for action, count := range counts {
-			actions = append(actions, fmt.Sprintf("%s %d", action, count))
-		}
-		sort.Strings(actions)
-
-		return fmt.Sprintf("[%s] %s %s wiki pages",
-			repo.Name,
-			sender.Login,
-			toSentence(actions),
-		)
-	}
+// Turns an alert action like "closed_by_user" into a verb.
+func alert_verb(action string) string {
+	action = strings.TrimSuffix(action, "_by_user")
+	action = strings.Replace(action, "auto_", "auto-", -1)
+	return strings.Replace(action, "_", " ", -1)
 }
 
 func toSentence(a []string) string {
@@ -521,9 +1459,9 @@ func toSentence(a []string) string {
 }
 
 func irc_push_summary_url(event *GHEvent) string {
-	distinct_commits := getDistinctCommits(event)
+	distinct_commits, _ := getDistinctCommits(event, nil)
 	repo_url := event.Repository.URL
-	before_sha := event.Before[0:7]
+	before_sha := shortSHA(event.Before)
 	if event.created() {
 		if len(distinct_commits) == 0 {
 			return repo_url + "/commits/" + event.ref_name()
@@ -548,10 +1486,20 @@ func irc_commit_comment_summary_url(event *GHEvent) string {
 func irc_pull_request_summary_url(event *GHEvent) string {
 	return event.PullRequest.HtmlUrl
 }
+func irc_pull_request_review_summary_url(event *GHEvent) string {
+	if event.Review.HtmlUrl != "" {
+		return event.Review.HtmlUrl
+	}
+	return event.PullRequest.HtmlUrl
+}
 func irc_pull_request_review_comment_summary_url(event *GHEvent) string {
 	return event.PullRequest.HtmlUrl
 }
 
+func irc_workflow_run_summary_url(event *GHEvent) string {
+	return event.WorkflowRun.HtmlUrl
+}
+
 func irc_issue_summary_url(event *GHEvent) string {
 	return event.Issue.HtmlUrl
 }
@@ -560,6 +1508,96 @@ func irc_issue_comment_summary_url(event *GHEvent) string {
 	return event.Issue.HtmlUrl
 }
 
+func irc_discussion_summary_url(event *GHEvent) string {
+	if event.Answer != nil && event.Answer.HtmlUrl != "" {
+		return event.Answer.HtmlUrl
+	}
+	return event.Discussion.HtmlUrl
+}
+
+func irc_discussion_comment_summary_url(event *GHEvent) string {
+	return event.Discussion.HtmlUrl
+}
+
+func irc_deployment_summary_url(event *GHEvent) string {
+	return event.Repository.web_url() + "/deployments"
+}
+
+func irc_deployment_status_summary_url(event *GHEvent) string {
+	status := event.DeploymentStatus
+	if status.State == "success" && status.EnvironmentUrl != "" {
+		return status.EnvironmentUrl
+	}
+	if status.LogUrl != "" {
+		return status.LogUrl
+	}
+	if status.TargetUrl != "" {
+		return status.TargetUrl
+	}
+	if status.EnvironmentUrl != "" {
+		return status.EnvironmentUrl
+	}
+	return irc_deployment_summary_url(event)
+}
+
+func irc_member_summary_url(event *GHEvent) string {
+	return event.Repository.web_url() + "/settings/access"
+}
+
+func irc_branch_protection_rule_summary_url(event *GHEvent) string {
+	return event.Repository.web_url() + "/settings/branches"
+}
+
+func irc_star_summary_url(event *GHEvent) string {
+	return event.Repository.web_url() + "/stargazers"
+}
+
+func irc_sponsorship_summary_url(event *GHEvent) string {
+	return "https://github.com/sponsors/" + event.Sponsorship.Sponsorable.Login
+}
+
+func irc_milestone_summary_url(event *GHEvent) string {
+	return event.Milestone.HtmlUrl
+}
+
+func irc_label_summary_url(event *GHEvent) string {
+	return event.Repository.web_url() + "/labels"
+}
+
+func irc_organization_summary_url(event *GHEvent) string {
+	return "https://github.com/" + event.Organization.Login
+}
+
+func irc_membership_summary_url(event *GHEvent) string {
+	if event.Team.HtmlUrl != "" {
+		return event.Team.HtmlUrl
+	}
+	return irc_organization_summary_url(event)
+}
+
+func irc_installation_summary_url(event *GHEvent) string {
+	return event.Installation.HtmlUrl
+}
+
+func irc_projects_v2_item_summary_url(event *GHEvent) string {
+	if event.Organization == nil {
+		return event.Repository.web_url() + "/projects"
+	}
+	url := "https://github.com/orgs/" + event.Organization.Login + "/projects"
+	if c := event.Changes; c != nil && c.FieldValue != nil && c.FieldValue.ProjectNumber != 0 {
+		url += fmt.Sprintf("/%d", c.FieldValue.ProjectNumber)
+	}
+	return url
+}
+
+func irc_alert_summary_url(event *GHEvent) string {
+	return event.Alert.HtmlUrl
+}
+
+func irc_repository_advisory_summary_url(event *GHEvent) string {
+	return event.RepositoryAdvisory.HtmlUrl
+}
+
 func irc_gollum_summary_url(event *GHEvent) string {
 	if len(event.Pages) == 1 {
 		return event.Pages[0].HtmlUrl
@@ -569,34 +1607,169 @@ func irc_gollum_summary_url(event *GHEvent) string {
 }
 
 func (data *EventFormatterOptions) branchNameMatches(event *GHEvent) bool {
-	if strings.TrimSpace(data.Branches) == "" {
+	if tag_name, ok := event.tag(); ok {
+		return listFilterMatches(data.Tags, tag_name)
+	}
+	branch_name, _ := event.branch()
+	return listFilterMatches(data.Branches, branch_name)
+}
+
+// Reports whether a name is allowed by a comma-separated list of patterns.
+// An empty list allows everything.
+// Negated patterns (starting with !) take precedence over the rest.
+func listFilterMatches(filter string, name string) bool {
+	if strings.TrimSpace(filter) == "" {
+		return true
+	}
+	matched, positive := false, false
+	for _, p := range strings.Split(filter, ",") {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "!") {
+			if matchPattern(p[1:], name) {
+				return false
+			}
+		} else if p != "" {
+			positive = true
+			matched = matched || matchPattern(p, name)
+		}
+	}
+	return matched || !positive
+}
+
+func (data *EventFormatterOptions) commentWidth() int {
+	if data.CommentWidth == 0 {
+		return 100
+	}
+	return data.CommentWidth
+}
+
+func (data *EventFormatterOptions) maxCommits() int {
+	switch {
+	case data.MaxCommits == 0:
+		return 3
+	case data.MaxCommits < 0:
+		return 0
+	default:
+		return data.MaxCommits
+	}
+}
+
+// Returns a copy of a push event with only the commits
+// which touched files matching data.Paths,
+// and only the matching files in each commit.
+// Returns nil if no commits match.
+func (data *EventFormatterOptions) filterPaths(event *GHEvent) *GHEvent {
+	var patterns []string
+	for _, p := range strings.Split(data.Paths, ",") {
+		p = strings.TrimSpace(p)
+		if strings.HasSuffix(p, "/") {
+			p += "**"
+		}
+		patterns = append(patterns, p)
+	}
+	filter := strings.Join(patterns, ",")
+	match := func(paths []string) []string {
+		var matched []string
+		for _, p := range paths {
+			if listFilterMatches(filter, p) {
+				matched = append(matched, p)
+			}
+		}
+		return matched
+	}
+
+	var commits []GHCommit
+	for _, c := range event.Commits {
+		c.Added = match(c.Added)
+		c.Modified = match(c.Modified)
+		c.Removed = match(c.Removed)
+		if len(c.files()) > 0 {
+			commits = append(commits, c)
+		}
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	filtered := *event
+	filtered.Commits = commits
+	return &filtered
+}
+
+// All the files a commit touched.
+func (commit *GHCommit) files() []string {
+	var files []string
+	files = append(files, commit.Added...)
+	files = append(files, commit.Modified...)
+	files = append(files, commit.Removed...)
+	return files
+}
+
+// The events BotMode applies to: the ones bots send a lot of.
+// Bots also create deployments and send alerts, and nobody wants to miss those.
+var botEvents = map[string]bool{
+	"push":                        true,
+	"commit_comment":              true,
+	"pull_request":                true,
+	"pull_request_review":         true,
+	"pull_request_review_comment": true,
+	"issues":                      true,
+	"issue_comment":               true,
+	"discussion":                  true,
+	"discussion_comment":          true,
+}
+
+func (data *EventFormatterOptions) ignoresSender(eventType string, event *GHEvent) bool {
+	login := event.Sender.Login
+	if login == "" {
+		return false
+	}
+	if listContains(data.IgnoreSenders, login) {
 		return true
 	}
-	branch_name := event.ref_name()
-	branches := strings.Split(data.Branches, ",")
-	for _, b := range branches {
-		if branch_name == b {
+	return botEvents[eventType] && event.Sender.isBot() && (data.BotMode == "" || data.BotMode == "ignore")
+}
+
+func (data *EventFormatterOptions) summarizesSender(event *GHEvent) bool {
+	login := event.Sender.Login
+	if login == "" {
+		return false
+	}
+	if listContains(data.SummarizeSenders, login) {
+		return true
+	}
+	return event.Sender.isBot() && data.BotMode == "summarize"
+}
+
+func (sender *GHSender) isBot() bool {
+	return sender.Type == "Bot" || strings.HasSuffix(sender.Login, "[bot]")
+}
+
+// Reports whether a comma-separated list of logins contains login.
+// Logins are case-insensitive.
+func listContains(list string, login string) bool {
+	for _, s := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(s), login) {
 			return true
 		}
 	}
 	return false
 }
 
-// Shortens the given URL with git.io.
-//
-// url - String URL to be shortened.
-//
-// Returns the String URL response from git.io.
-func shorten_url(url string) string {
-	c := &http.Client{
-		Timeout: 15 * time.Second,
+func (data *EventFormatterOptions) issueActionMatches(event *GHEvent) bool {
+	if strings.TrimSpace(data.IssueActions) == "" {
+		return false
 	}
-	resp, err := c.PostForm("https://git.io", urlpkg.Values{"url": []string{url}})
-	if err == nil && resp.StatusCode == 201 {
-		return resp.Header.Get("Location")
-	} else {
-		return url
+	actions := strings.Split(data.IssueActions, ",")
+	for _, a := range actions {
+		if event.Action == strings.TrimSpace(a) {
+			return true
+		}
 	}
+	return false
+}
+
+func (data *EventFormatterOptions) environmentMatches(event *GHEvent) bool {
+	return listFilterMatches(data.Environments, event.Deployment.environment(event))
 }
 
 func partition(s, sep string) (head, tail string) {
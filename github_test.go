@@ -0,0 +1,223 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The event types FormatGithubEvent knows how to format.
+var formattedEvents = []string{
+	"push", "commit_comment", "pull_request", "pull_request_review", "pull_request_review_comment",
+	"issues", "issue_comment", "discussion", "discussion_comment", "deployment", "deployment_status",
+	"member", "repository", "public", "branch_protection_rule", "team_add", "star",
+	"sponsorship", "milestone", "label", "projects_v2_item", "gollum", "dependabot_alert",
+	"code_scanning_alert", "secret_scanning_alert", "repository_advisory", "workflow_run",
+	"organization", "membership", "installation",
+}
+
+// Formatting an event should never panic, whatever the payload is missing.
+func FuzzFormatGithubEvent(f *testing.F) {
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`{"action":"opened","issue":{},"pull_request":{},"comment":{},"review":{},"pages":[{}],"commits":[{"distinct":true,"message":"x"}],"before":"1","after":"2","created":true,"deleted":true,"forced":true}`))
+	f.Add([]byte(`{"action":"edited","changes":{"body":{},"field_value":{},"name":{},"owner":{"from":{}},"repository":{"name":{}}}}`))
+	f.Add([]byte(`{"action":"closed","pull_request":{"merged":true,"head":{"repo":{}}},"workflow_run":{"conclusion":"failure","pull_requests":[{}]}}`))
+	f.Add([]byte(`{"action":"created","label":{},"milestone":{"due_on":"2"},"projects_v2_item":{},"alert":{},"repository_advisory":{},"deployment":{},"deployment_status":{},"member":{},"rule":{},"team":{},"sponsorship":{"tier":{}},"discussion":{},"membership":{},"invitation":{},"installation":{}}`))
+	f.Add([]byte(`{"action":"member_added","organization":{"login":"o"},"sender":{"login":"dependabot[bot]"},"commits":[{"id":"a","distinct":true,"message":"[skip irc]","timestamp":"2001-01-01T00:00:00Z","committer":{"name":"b"}}]}`))
+
+	opts := []*EventFormatterOptions{
+		nil,
+		{LongURL: true, Locale: "de", Format: "markdown", IssueActions: "labeled,unlabeled,assigned,unassigned,milestoned,demilestoned,transferred,edited"},
+		{LongURL: true, Locale: "ja", Format: "html", Paths: "docs/,*.go", BotMode: "show", OldCommitDays: 1},
+		{LongURL: true, Format: "json", Branches: "main", Environments: "production", RepoNames: "full", MaxCommits: -1},
+		{LongURL: true, Format: "plain", BotMode: "summarize", NoHighlights: true, HighlightAuthors: true, CommentWidth: -1, EditWindow: "0"},
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		event, err := ParseGithubEvent(body)
+		if err != nil {
+			return
+		}
+		for _, eventType := range formattedEvents {
+			for _, cfg := range opts {
+				FormatGithubEvent(eventType, event, cfg)
+			}
+		}
+	})
+}
+
+func parseEvent(t *testing.T, body string) *GHEvent {
+	t.Helper()
+	event, err := ParseGithubEvent([]byte(body))
+	if err != nil {
+		t.Fatalf("parsing %s: %v", body, err)
+	}
+	return event
+}
+
+func TestListFilterMatches(t *testing.T) {
+	tests := []struct {
+		filter, name string
+		want         bool
+	}{
+		{"", "anything", true},
+		{" , ", "anything", true},
+		{"main", "main", true},
+		{"main", "maintenance", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/rc", false},
+		{"release/*", "release", false},
+		{"release/**", "release/1.0/rc", true},
+		{"**/fix", "fix", true},
+		{"**/fix", "user/bob/fix", true},
+		{"v?", "v1", true},
+		{"v?", "v10", false},
+		{"v[0-9]*", "v2.1", true},
+		{"v[!0-9]*", "v2.1", false},
+		{`/^release-[0-9.]+$/`, "release-1.2", true},
+		{`/^release-[0-9.]+$/`, "release-x", false},
+		{"main, develop", "develop", true},
+		{"!dependabot/**", "main", true},
+		{"!dependabot/**", "dependabot/npm/lodash-4.17", false},
+		{"*, !dependabot/**", "dependabot/npm", false},
+		{"!dependabot/**, dependabot/npm", "dependabot/npm", false},
+		{"main, !main", "main", false},
+		{`\*`, "*", true},
+		{`\*`, "x", false},
+	}
+	for _, tt := range tests {
+		if got := listFilterMatches(tt.filter, tt.name); got != tt.want {
+			t.Errorf("listFilterMatches(%q, %q) = %v, want %v", tt.filter, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBranchNameMatches(t *testing.T) {
+	push := func(ref string) string {
+		return `{"ref":"` + ref + `"}`
+	}
+	pr := func(base string) string {
+		return `{"action":"opened","pull_request":{"base":{"ref":"` + base + `"},"head":{"ref":"feature"}}}`
+	}
+	tests := []struct {
+		branches, tags string
+		body           string
+		want           bool
+	}{
+		{"", "", push("refs/heads/main"), true},
+		{"main", "", push("refs/heads/main"), true},
+		{"main", "", push("refs/heads/feature"), false},
+		{"!dependabot/**", "", push("refs/heads/dependabot/go/x"), false},
+
+		// Tags are filtered by Tags alone.
+		{"main", "", push("refs/tags/v1.0"), true},
+		{"main", "v*", push("refs/tags/v1.0"), true},
+		{"", "v*", push("refs/tags/nightly"), false},
+		{"", "v*", push("refs/heads/nightly"), true},
+
+		// Pull requests are filtered by their base branch.
+		{"main", "", pr("main"), true},
+		{"main", "", pr("release/1.0"), false},
+		{"release/*", "", pr("release/1.0"), true},
+		{"feature", "", pr("main"), false},
+	}
+	for _, tt := range tests {
+		cfg := &EventFormatterOptions{Branches: tt.branches, Tags: tt.tags}
+		if got := cfg.branchNameMatches(parseEvent(t, tt.body)); got != tt.want {
+			t.Errorf("Branches %q, Tags %q: branchNameMatches(%s) = %v, want %v", tt.branches, tt.tags, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestStripSkipMarkers(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+		found   bool
+	}{
+		{"Fix the build", "Fix the build", false},
+		{"Fix the build [skip irc]", "Fix the build", true},
+		{"[skip irc] Fix the build", "Fix the build", true},
+		{"Fix [SKIP IRC] the build", "Fix the build", true},
+		{"Fix [skip irc][skip irc] the build", "Fix the build", true},
+		{"Bump version\n\nNotify: no", "Bump version", true},
+		{"Bump version\n\n  notify: NO  ", "Bump version", true},
+		{"Bump version\n\nNotify: no, thanks", "Bump version\n\nNotify: no, thanks", false},
+		{"Bump version\n\nWhy? Notify: no\n", "Bump version\n\nWhy? Notify: no\n", false},
+	}
+	for _, tt := range tests {
+		got, found := stripSkipMarkers(tt.message, defaultSkipMarkers)
+		if got != tt.want || found != tt.found {
+			t.Errorf("stripSkipMarkers(%q) = %q, %v; want %q, %v", tt.message, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSkippedCommits(t *testing.T) {
+	const (
+		skipped = `{"id":"1111111","distinct":true,"message":"Tidy up [skip irc]"}`
+		trailer = `{"id":"2222222","distinct":true,"message":"Bump version\n\nNotify: no"}`
+		shown   = `{"id":"3333333","distinct":true,"message":"Fix the [irc skip] parser"}`
+		plain   = `{"id":"4444444","distinct":true,"message":"Add a feature"}`
+	)
+	push := func(commits ...string) *GHEvent {
+		return parseEvent(t, `{"ref":"refs/heads/main","before":"a","after":"b","pusher":{"name":"bob"},"repository":{"name":"r"},"commits":[`+strings.Join(commits, ",")+`]}`)
+	}
+	cfg := &EventFormatterOptions{LongURL: true, NoColors: true}
+
+	if msg := FormatGithubEvent("push", push(skipped, trailer), cfg); msg != "" {
+		t.Errorf("all skipped: got %q, want nothing", msg)
+	}
+
+	commits, all := getDistinctCommits(push(skipped, shown, plain), cfg)
+	if all {
+		t.Error("mixed push: every commit was skipped")
+	}
+	var messages []string
+	for _, c := range commits {
+		messages = append(messages, c.Message)
+	}
+	want := []string{"Tidy up", "Fix the parser", "Add a feature"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("mixed push: got %q, want %q", messages, want)
+	}
+	msg := FormatGithubEvent("push", push(skipped, shown, plain), cfg)
+	if msg == "" || strings.Contains(msg, "skip") {
+		t.Errorf("mixed push: got %q", msg)
+	}
+
+	none := &EventFormatterOptions{LongURL: true, NoColors: true, SkipMarkers: "none"}
+	commits, all = getDistinctCommits(push(skipped, trailer), none)
+	if all || len(commits) != 2 || commits[0].Message != "Tidy up [skip irc]" {
+		t.Errorf("SkipMarkers none: got %d commits, all skipped %v", len(commits), all)
+	}
+	if msg := FormatGithubEvent("push", push(skipped), none); !strings.Contains(msg, "[skip irc]") {
+		t.Errorf("SkipMarkers none: got %q", msg)
+	}
+}
+
+func TestFormatGithubEvents(t *testing.T) {
+	const repo = `"repository":{"name":"app","full_name":"acme/app"}`
+	merged := func(headRepo string) BurstEvent {
+		return BurstEvent{"pull_request", parseEvent(t, `{"action":"closed","pull_request":{"number":7,"title":"Fix it","merged":true,"merge_commit_sha":"m1","commits":2,"base":{"ref":"main"},"head":{"ref":"fix","repo":{"full_name":"`+headRepo+`"}}},"sender":{"login":"alice"},`+repo+`}`)}
+	}
+	push := func(branch, after string) BurstEvent {
+		return BurstEvent{"push", parseEvent(t, `{"ref":"refs/heads/`+branch+`","before":"a","after":"`+after+`","pusher":{"name":"alice"},"commits":[{"id":"`+after+`","distinct":true,"message":"Change"}],`+repo+`}`)}
+	}
+	del := BurstEvent{"delete", parseEvent(t, `{"ref":"fix","ref_type":"branch",`+repo+`}`)}
+	cfg := &EventFormatterOptions{LongURL: true, NoColors: true}
+
+	msgs := FormatGithubEvents([]BurstEvent{merged("acme/app"), push("main", "m1"), del}, cfg)
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "merged pull request #7 into main (2 commits), deleted fix") {
+		t.Errorf("merge: got %q, want one combined message", msgs)
+	}
+
+	msgs = FormatGithubEvents([]BurstEvent{push("develop", "d1"), merged("acme/app"), push("main", "m1"), push("main", "m2")}, cfg)
+	if len(msgs) != 3 || !strings.Contains(msgs[0], "develop") || !strings.Contains(msgs[1], "merged pull request #7") || !strings.Contains(msgs[2], "main") {
+		t.Errorf("unrelated pushes: got %q, want them kept apart from the merge", msgs)
+	}
+
+	msgs = FormatGithubEvents([]BurstEvent{merged("bob/app"), push("main", "m1"), del}, cfg)
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "merged pull request #7") || strings.Contains(msgs[0], "deleted") {
+		t.Errorf("fork: got %q, want the merge without the deletion", msgs)
+	}
+}
@@ -16,7 +16,8 @@ type IRC struct {
 	log       *log.Logger
 	connected chan struct{} // closed when the connection handshake is finished
 	nick      string
-	channel   string // TODO: multiple channels
+	channel   string   // default channel
+	channels  []string // additional channels
 }
 
 // TODO: what to do upon disconnection?
@@ -45,16 +46,49 @@ func (c *IRC) SetLogger(logger *log.Logger) {
 	c.log = logger
 }
 
-// Set the channel to connect to on login.
+// Set the default channel to connect to on login.
 // Must be called before Run.
 func (c *IRC) SetChannel(channel string) {
 	if channel == "" {
 		return
 	}
+	c.channel = channelName(channel)
+}
+
+// Get the default channel.
+func (c *IRC) Channel() string {
+	return c.channel
+}
+
+// Add another channel to connect to on login.
+// Must be called before Run.
+func (c *IRC) AddChannel(channel string) {
+	if channel == "" {
+		return
+	}
+	channel = channelName(channel)
+	if sameChannel(channel, c.channel) {
+		return
+	}
+	for _, ch := range c.channels {
+		if sameChannel(ch, channel) {
+			return
+		}
+	}
+	c.channels = append(c.channels, channel)
+}
+
+func channelName(channel string) string {
 	if !strings.HasPrefix(channel, "#") {
 		channel = "#" + channel
 	}
-	c.channel = channel
+	return channel
+}
+
+// Reports whether two channel names refer to the same channel.
+// Channel names are case-insensitive.
+func sameChannel(a, b string) bool {
+	return strings.EqualFold(channelName(a), channelName(b))
 }
 
 func (c *IRC) handle(m *irc.Message) {
@@ -65,6 +99,12 @@ func (c *IRC) handle(m *irc.Message) {
 			Command: "JOIN",
 			Params:  []string{c.channel},
 		})
+		for _, channel := range c.channels {
+			c.send(&irc.Message{
+				Command: "JOIN",
+				Params:  []string{channel},
+			})
+		}
 		close(c.connected)
 	} else if m.Command == "PRIVMSG" && m.Trailing() == "!quit" {
 		io.WriteString(c.conn, "QUIT")
@@ -121,17 +161,26 @@ func (c *IRC) runLoop() error {
 	}
 }
 
-// Send a message to all channels.
+// Send a message to the default channel.
 // If message contains newlines, it will be split into multiple messages.
 // Safe to call concurrently.
 func (c *IRC) Announce(msg string) error {
+	return c.AnnounceTo(c.channel, msg)
+}
+
+// Send a message to the given channel,
+// which should be the default channel or one added with AddChannel.
+// If message contains newlines, it will be split into multiple messages.
+// Safe to call concurrently.
+func (c *IRC) AnnounceTo(channel string, msg string) error {
 	<-c.connected
+	channel = channelName(channel)
 	if strings.Contains(msg, "\n") {
 		for _, line := range strings.Split(msg, "\n") {
 			if line != "" {
 				err := c.send(&irc.Message{
 					Command: "PRIVMSG",
-					Params:  []string{c.channel, line},
+					Params:  []string{channel, line},
 				})
 				if err != nil {
 					return err
@@ -142,7 +191,7 @@ func (c *IRC) Announce(msg string) error {
 	} else {
 		return c.send(&irc.Message{
 			Command: "PRIVMSG",
-			Params:  []string{c.channel, msg},
+			Params:  []string{channel, msg},
 		})
 	}
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// A catalog is what it takes to write messages in a language.
+type catalog struct {
+	// Templates in place of defaultTemplates, by name.
+	// A catalog should translate every template with words in it:
+	// its plural rule and words won't suit the English ones.
+	templates map[string]string
+
+	// Which of a word's forms to use for n of something.
+	// Templates list the forms in the language's order, like {{plural $n "Commit" "Commits"}};
+	// if there are fewer forms than the rule wants, the last one is used.
+	plural func(n int) int
+
+	// Lists things, like "a, b, and c".
+	toSentence func(a []string) string
+
+	// Shortens a run of mentions to the first one and a count of the others,
+	// like "@a and 2 others".
+	others func(first string, n int) string
+
+	// What goes between groups of three digits, like the comma in 1,204.
+	thousands string
+
+	// Formats a duration, like "hour" or "10 minutes", for star.burst.
+	window func(d time.Duration) string
+
+	// Says how long ago something was, like "3 weeks ago",
+	// given a number of days, weeks, months or years (see ageUnits).
+	ago func(n int, unit string) string
+
+	// Words that come straight from payloads, like actions, states and severities.
+	// Templates translate them with tr; words that aren't listed are humanized.
+	words map[string]string
+
+	// The default templates, overridden by the catalog's.
+	base *template.Template
+}
+
+// The catalogs, by language code.
+// Locales like "de-DE" use the catalog for their language.
+var catalogs = map[string]*catalog{
+	"en": englishCatalog,
+	"de": germanCatalog,
+	"ja": japaneseCatalog,
+}
+
+var englishCatalog = &catalog{
+	plural: func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	},
+	toSentence: toSentence,
+	others: func(first string, n int) string {
+		return first + " and " + fmt_count(n) + " " + plural(n, "other", "others")
+	},
+	thousands: ",",
+	window:    fmt_window,
+	ago: func(n int, unit string) string {
+		return fmt.Sprintf("%d %s ago", n, plural(n, unit, unit+"s"))
+	},
+}
+
+func init() {
+	for lang, c := range catalogs {
+		if len(c.templates) == 0 {
+			c.base = baseTemplates
+			continue
+		}
+		for name := range c.templates {
+			if _, ok := defaultTemplates[name]; !ok {
+				panic(fmt.Sprintf("%s catalog: unknown template %q", lang, name))
+			}
+		}
+		c.base = template.Must(parseTemplates(template.Must(baseTemplates.Clone()), c.templates))
+	}
+}
+
+// The language part of a locale, like "de" for "de-DE" or "de_AT.UTF-8".
+func localeLanguage(locale string) string {
+	if i := strings.IndexAny(locale, "-_."); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(locale)
+}
+
+func (data *EventFormatterOptions) checkLocale() error {
+	if data.Locale == "" {
+		return nil
+	}
+	if _, ok := catalogs[localeLanguage(data.Locale)]; !ok {
+		return fmt.Errorf("unknown Locale %q", data.Locale)
+	}
+	return nil
+}
+
+// The catalog to write messages with.
+func (data *EventFormatterOptions) catalog() *catalog {
+	if c, ok := catalogs[localeLanguage(data.Locale)]; ok {
+		return c
+	}
+	return englishCatalog
+}
+
+// Pick the form of a word for n of something.
+func (c *catalog) pluralForm(n int, forms ...string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+	i := c.plural(n)
+	if i >= len(forms) {
+		i = len(forms) - 1
+	}
+	return forms[i]
+}
+
+// Format a number with the catalog's thousands separator.
+func (c *catalog) count(n int) string {
+	return strings.Replace(fmt_count(n), ",", c.thousands, -1)
+}
+
+// Translate a word from a payload.
+// The English catalog leaves them alone.
+func (c *catalog) tr(word string) string {
+	if c.words == nil {
+		return word
+	}
+	if w, ok := c.words[word]; ok {
+		return w
+	}
+	if w, ok := c.words[strings.ToLower(word)]; ok {
+		return w
+	}
+	return strings.Replace(word, "_", " ", -1)
+}
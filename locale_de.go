@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var germanCatalog = &catalog{
+	plural: func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	},
+	toSentence: func(a []string) string {
+		if len(a) < 2 {
+			return strings.Join(a, "")
+		}
+		return strings.Join(a[:len(a)-1], ", ") + " und " + a[len(a)-1]
+	},
+	others: func(first string, n int) string {
+		return first + " und " + fmt_count(n) + " " + plural(n, "weiterer", "weitere")
+	},
+	thousands: ".",
+	window: func(d time.Duration) string {
+		switch {
+		case d == time.Hour:
+			return "einer Stunde"
+		case d == time.Minute:
+			return "einer Minute"
+		case d%time.Hour == 0:
+			return fmt.Sprintf("%d Stunden", d/time.Hour)
+		case d%time.Minute == 0:
+			return fmt.Sprintf("%d Minuten", d/time.Minute)
+		default:
+			return d.String()
+		}
+	},
+	ago: func(n int, unit string) string {
+		units := map[string][2]string{
+			"day":   {"Tag", "Tagen"},
+			"week":  {"Woche", "Wochen"},
+			"month": {"Monat", "Monaten"},
+			"year":  {"Jahr", "Jahren"},
+		}[unit]
+		return fmt.Sprintf("vor %d %s", n, plural(n, units[0], units[1]))
+	},
+
+	words: map[string]string{
+		// actions
+		"opened":             "geöffnet",
+		"closed":             "geschlossen",
+		"reopened":           "wieder geöffnet",
+		"edited":             "bearbeitet",
+		"created":            "erstellt",
+		"deleted":            "gelöscht",
+		"transferred":        "übertragen",
+		"pinned":             "angeheftet",
+		"unpinned":           "losgelöst",
+		"locked":             "gesperrt",
+		"unlocked":           "entsperrt",
+		"synchronize":        "aktualisiert",
+		"ready_for_review":   "als bereit zum Review markiert",
+		"converted_to_draft": "in einen Entwurf umgewandelt",
+		"review_requested":   "zum Review vorgelegt",
+		"answered":           "beantwortet",
+		"unanswered":         "als unbeantwortet markiert",
+		"category_changed":   "in eine andere Kategorie verschoben",
+		"archived":           "archiviert",
+		"unarchived":         "aus dem Archiv geholt",
+		"renamed":            "umbenannt",
+		"restored":           "wiederhergestellt",
+		"reordered":          "verschoben",
+		"cancelled":          "beendet",
+		"published":          "veröffentlicht",
+		"reported":           "gemeldet",
+		"withdrawn":          "zurückgezogen",
+		"updated":            "aktualisiert",
+		"dismissed":          "verworfen",
+		"fixed":              "behoben",
+		"auto-dismissed":     "automatisch verworfen",
+		"auto-reopened":      "automatisch wieder geöffnet",
+		"resolved":           "erledigt",
+		"reintroduced":       "wieder eingeführt",
+		"appeared in branch": "in einem Branch gefunden",
+
+		// states
+		"open":           "offen",
+		"auto_dismissed": "automatisch verworfen",
+		"draft":          "Entwurf",
+		"triage":         "in Prüfung",
+		"success":        "erfolgreich",
+		"failure":        "fehlgeschlagen",
+		"error":          "Fehler",
+		"pending":        "ausstehend",
+		"in_progress":    "läuft",
+		"queued":         "in der Warteschlange",
+		"inactive":       "inaktiv",
+
+		// severities
+		"critical": "kritisch",
+		"high":     "hoch",
+		"medium":   "mittel",
+		"moderate": "mittel",
+		"low":      "niedrig",
+		"warning":  "Warnung",
+		"note":     "Hinweis",
+
+		// resolutions of secret scanning alerts
+		"false_positive":  "Fehlalarm",
+		"wont_fix":        "wird nicht behoben",
+		"revoked":         "widerrufen",
+		"used_in_tests":   "in Tests verwendet",
+		"pattern_edited":  "Muster bearbeitet",
+		"pattern_deleted": "Muster gelöscht",
+	},
+
+	templates: map[string]string{
+		"push": `[{{repo .Repo}}] {{if .Event.Pusher.Name}}{{name .Actor}}{{else}}jemand{{end}}
+		{{- $e := .Event}}{{$n := len .Commits}}
+		{{- if created $e}}
+			{{- if isTag $e}} hat {{tag (refName $e)}} auf {{if $e.BaseRef}}{{branch (baseRefName $e)}}{{else}}{{hash (shortSHA $e.After)}}{{end}} getaggt
+			{{- else}} hat {{branch (refName $e)}}
+				{{- if $e.BaseRef}} von {{branch (baseRefName $e)}} aus{{else if eq $n 0}} auf {{hash (shortSHA $e.After)}}{{end}} erstellt
+				{{- ""}} (+{{bold (print $n)}} {{plural $n "neuer Commit" "neue Commits"}})
+			{{- end}}
+		{{- else if deleted $e}} hat {{branch (refName $e)}} auf {{hash (shortSHA $e.Before)}} {{warning "gelöscht"}}
+		{{- else if forced $e}} hat {{branch (refName $e)}} von {{hash (shortSHA $e.Before)}} auf {{hash (shortSHA $e.After)}} {{warning "force-gepusht"}}
+		{{- else if and $e.Commits (eq $n 0)}}
+			{{- if $e.BaseRef}} hat {{branch (baseRefName $e)}} in {{branch (refName $e)}} gemergt
+			{{- else}} hat {{branch (refName $e)}} per Fast-Forward von {{hash (shortSHA $e.Before)}} auf {{hash (shortSHA $e.After)}} gebracht
+			{{- end}}
+		{{- else}} hat {{bold (print $n)}} {{plural $n "neuen Commit" "neue Commits"}} nach {{branch (refName $e)}} gepusht
+		{{- end}}: {{url .URL}}`,
+
+		"push.commit": `{{repo .Repo}}/{{branch (refName .Event)}} {{hash (shortSHA .Commit.ID)}} {{name .Commit.Author.Name}}
+		{{- $by := committer .Commit}}{{$age := authored .Commit}}
+		{{- if or $by $age}} ({{with $by}}committet von {{name .}}{{if $age}}, {{end}}{{end}}{{with $age}}verfasst {{.}}{{end}}){{end}}: {{firstLine .Commit.Message}}
+		{{- if .Files}} ({{.Files}} {{plural .Files "Datei" "Dateien"}}){{end}}`,
+
+		"push.more": `... und {{.Count}} {{if .Newest}}{{plural .Count "älterer" "ältere"}}{{else}}{{plural .Count "weiterer" "weitere"}}{{end}} {{plural .Count "Commit" "Commits"}}
+		{{- if .URL}}: {{url .URL}}{{end}}`,
+
+		"commit_comment": `[{{repo .Repo}}] {{name .Actor}} hat Commit {{hash (shortSHA .Event.Comment.CommitID)}} kommentiert{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"pull_request": `{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} hat Pull-Request #{{.Number}} {{tr $.Action}}: {{.Title}}
+		{{- ""}} ({{branch .Base.Ref}}...{{if eq .Head.Ref .Base.Ref}}{{branch .Head.Label}}{{else}}{{branch .Head.Ref}}{{end}})
+		{{- if eq $.Action "edited"}} {{template "edited" $}}{{end}} {{url $.URL}}{{end}}`,
+
+		"pull_request.merged": `{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} hat Pull-Request #{{.Number}} in {{branch .Base.Ref}} gemergt
+		{{- with $.Count}} ({{bold (print .)}} {{plural . "Commit" "Commits"}}){{end}}
+		{{- if $.Deleted}}, {{branch .Head.Ref}} {{warning "gelöscht"}}{{end}}: {{.Title}} {{url $.URL}}{{end}}`,
+
+		"pull_request_review": `{{with .Highlight}}{{.}}: {{end}}{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- $state := $.Event.Review.State}}
+		{{- if eq $state "approved"}} hat Pull-Request #{{.Number}} genehmigt
+		{{- else if eq $state "changes_requested"}} hat Änderungen an Pull-Request #{{.Number}} angefordert
+		{{- else}} hat Pull-Request #{{.Number}} reviewt
+		{{- end}}{{with summarize $.Event.Review.Body}}: {{.}}{{end}} {{url $.URL}}{{end}}`,
+
+		"pull_request_review_comment": `[{{repo .Repo}}] {{name .Actor}} hat
+		{{- if eq .Action "edited"}} einen Kommentar zu Pull-Request #{{.Event.PullRequest.Number}} {{hash (shortSHA .Event.Comment.CommitID)}} bearbeitet {{template "edited" .}}
+		{{- else}} Pull-Request #{{.Event.PullRequest.Number}} {{hash (shortSHA .Event.Comment.CommitID)}} kommentiert
+		{{- end}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"issues": `[{{repo .Repo}}] {{name .Actor}} hat Issue #{{.Event.Issue.Number}} {{tr .Action}}{{if eq .Action "edited"}} {{template "edited" .}}{{end}}: {{.Event.Issue.Title}} {{url .URL}}`,
+
+		"issues.triage": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $e := .Event}}{{$issue := .Event.Issue}}
+		{{- if eq .Action "labeled"}} hat Issue #{{$issue.Number}} als {{with $e.Label}}{{label .Name .Color}}{{end}} markiert
+		{{- else if eq .Action "unlabeled"}} hat das Label {{with $e.Label}}{{label .Name .Color}}{{end}} von Issue #{{$issue.Number}} entfernt
+		{{- else if eq .Action "assigned"}} hat Issue #{{$issue.Number}} {{with $e.Assignee}}{{name .Login}}{{end}} zugewiesen
+		{{- else if eq .Action "unassigned"}} hat die Zuweisung von Issue #{{$issue.Number}} an {{with $e.Assignee}}{{name .Login}}{{end}} aufgehoben
+		{{- else if eq .Action "milestoned"}} hat Issue #{{$issue.Number}} zum Meilenstein {{template "issues.milestone" .}} hinzugefügt
+		{{- else if eq .Action "demilestoned"}} hat Issue #{{$issue.Number}} aus dem Meilenstein {{template "issues.milestone" .}} entfernt
+		{{- else if eq .Action "transferred"}} hat Issue #{{$issue.Number}}
+			{{- with $e.Changes}}{{if .NewRepository}} nach {{repo .NewRepository.FullName}}{{with .NewIssue}}#{{.Number}}{{end}}{{end}}{{end}} übertragen
+		{{- else}} hat Issue #{{$issue.Number}} {{tr .Action}}
+		{{- end}}: {{$issue.Title}}
+		{{- if and $issue.Milestone (ne .Action "milestoned") (ne .Action "demilestoned")}} (Meilenstein {{bold $issue.Milestone.Title}}){{end}} {{url .URL}}`,
+
+		"issue_comment": `[{{repo .Repo}}] {{name .Actor}} hat
+		{{- if eq .Action "edited"}} einen Kommentar zu Issue #{{.Event.Issue.Number}} bearbeitet {{template "edited" .}}
+		{{- else}} Issue #{{.Event.Issue.Number}} kommentiert
+		{{- end}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"discussion": `{{with .Event.Discussion}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- if eq $.Action "created"}} hat Diskussion #{{.Number}}{{if .Category.Name}} in {{.Category.Name}}{{end}} gestartet
+		{{- else}} hat Diskussion #{{.Number}} {{tr $.Action}}
+		{{- end}}: {{.Title}} {{url $.URL}}{{end}}`,
+
+		"discussion_comment": `[{{repo .Repo}}] {{name .Actor}} hat
+		{{- if eq .Action "edited"}} einen Kommentar zu Diskussion #{{.Event.Discussion.Number}} bearbeitet {{template "edited" .}}
+		{{- else}} Diskussion #{{.Event.Discussion.Number}} kommentiert
+		{{- end}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"edited": `(+{{.Added}}/-{{.Removed}} Zeilen)`,
+
+		"deployment": `{{with .Event.Deployment}}[{{repo $.Repo}}] {{name $.Actor}} deployt {{branch .Ref}} ({{hash (shortSHA .SHA)}}) nach {{environment $.Event}} {{url $.URL}}{{end}}`,
+
+		"deployment_status": `{{with .Event.Deployment}}[{{repo $.Repo}}] Deployment von {{branch .Ref}} ({{hash (shortSHA .SHA)}}) nach {{environment $.Event}} durch {{name .Creator.Login}}
+		{{- end}}{{with .Event.DeploymentStatus}}: {{state .State}}{{if .Description}} - {{firstLine .Description}}{{end}} {{url $.URL}}{{end}}`,
+
+		"member": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $member := .Event.Member.Login}}
+		{{- if eq .Action "added"}} hat {{name $member}} als Mitarbeiter hinzugefügt
+		{{- else if eq .Action "removed"}} hat {{name $member}} als Mitarbeiter entfernt
+		{{- else}} hat die Berechtigungen von Mitarbeiter {{name $member}}
+			{{- with .Event.Changes}}{{with .Permission}}{{if .To}} von {{.From}} auf {{.To}}{{end}}{{end}}{{end}} geändert
+		{{- end}} {{url .URL}}`,
+
+		"repository": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $c := .Event.Changes}}{{$from := formerOwner .Event}}
+		{{- if and (eq .Action "renamed") $c $c.Repository $c.Repository.Name}} hat das Repository von {{repo $c.Repository.Name.From}} in {{repo .Event.Repository.Name}} umbenannt
+		{{- else if and (eq .Action "transferred") $from}} hat das Repository von {{name $from}} an {{name .Event.Repository.Owner.Login}} übertragen
+		{{- else if eq .Action "publicized"}} hat das Repository {{bold "öffentlich"}} gemacht
+		{{- else if eq .Action "privatized"}} hat das Repository {{bold "privat"}} gemacht
+		{{- else}} hat das Repository {{tr .Action}}
+		{{- end}} {{url .URL}}`,
+
+		"public": `[{{repo .Repo}}] {{name .Actor}} hat das Repository {{bold "öffentlich"}} gemacht {{url .URL}}`,
+
+		"branch_protection_rule": `[{{repo .Repo}}] {{name .Actor}} hat die Branch-Schutzregel für {{branch .Event.Rule.Name}} {{tr .Action}} {{url .URL}}`,
+
+		"team_add": `[{{repo .Repo}}] {{name .Actor}} hat Team {{name .Event.Team.Name}} Zugriff auf das Repository gegeben {{url .URL}}`,
+
+		"star": `{{$n := .Event.Repository.StargazersCount}}[{{repo .Repo}}] {{name .Actor}} hat dem Repository einen Stern gegeben (jetzt {{count $n}} {{plural $n "Stern" "Sterne"}}) {{url .URL}}`,
+
+		"star.burst": `[{{repo .Repo}}] {{bold (printf "%+d" .Count)}} {{plural .Count "Stern" "Sterne"}} in {{window .Window}} (jetzt {{count .Event.Repository.StargazersCount}}) {{url .URL}}`,
+
+		"digest": `{{if eq .Action "weekly"}}Letzte Woche{{else}}Gestern{{end}} in {{repo .Repo}}
+		{{- $sep := ": "}}{{with .Digest}}{{$people := len .Authors}}
+		{{- with .Commits}}{{$sep}}{{bold (print .)}} {{plural . "Commit" "Commits"}} von {{$people}} {{plural $people "Person" "Personen"}}{{$sep = ", "}}{{end}}
+		{{- with .PullsMerged}}{{$sep}}{{.}} {{plural . "PR" "PRs"}} gemergt{{$sep = ", "}}{{end}}
+		{{- with .PullsOpened}}{{$sep}}{{.}} {{plural . "PR" "PRs"}} geöffnet{{$sep = ", "}}{{end}}
+		{{- with .IssuesOpened}}{{$sep}}{{.}} {{plural . "Issue" "Issues"}} geöffnet{{$sep = ", "}}{{end}}
+		{{- with .IssuesClosed}}{{$sep}}{{.}} {{plural . "Issue" "Issues"}} geschlossen{{$sep = ", "}}{{end}}
+		{{- with .Releases}}{{$sep}}{{.}} {{plural . "Release" "Releases"}}{{$sep = ", "}}{{end}}
+		{{- end}}`,
+
+		"sponsorship": `[{{repo .Repo}}] {{template "sponsorship.sponsor" .}}
+		{{- if eq .Action "created"}} sponsert jetzt {{name .Repo}}{{template "sponsorship.tier" .}}
+		{{- else if eq .Action "tier_changed"}} hat das Sponsoring von {{name .Repo}} geändert{{template "sponsorship.tier" .}}
+		{{- else}} hat das Sponsoring von {{name .Repo}} {{tr .Action}}
+		{{- end}} {{url .URL}}`,
+
+		"sponsorship.sponsor": `{{if eq .Event.Sponsorship.PrivacyLevel "private"}}ein privater Sponsor{{else}}{{name .Actor}}{{end}}`,
+
+		"sponsorship.tier": `{{with .Event.Sponsorship.Tier}}{{if .Name}} ({{.Name}}){{else if gt .MonthlyPriceInDollars 0}} ({{.MonthlyPriceInDollars}} $ im Monat){{end}}{{end}}`,
+
+		"milestone": `{{with .Event.Milestone}}[{{repo $.Repo}}] {{name $.Actor}} hat den Meilenstein {{bold .Title}} {{tr $.Action}}
+		{{- if and (ge (len .DueOn) 10) (ne $.Action "closed") (ne $.Action "deleted")}} (fällig am {{slice .DueOn 0 10}}){{end}} {{url $.URL}}{{end}}`,
+
+		"label": `{{with .Event.Label}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- if eq $.Action "edited"}} hat das Label {{$.Event.Changes.Name.From}} in {{label .Name .Color}} umbenannt
+		{{- else}} hat das Label {{label .Name .Color}} {{tr $.Action}}
+		{{- end}} {{url $.URL}}{{end}}`,
+
+		"organization": `[{{repo .Repo}}] {{name .Actor}}
+		{{- if eq .Action "member_added"}}{{with .Event.Membership}} hat {{name .User.Login}}{{if eq .Role "admin"}} als {{bold "Owner"}}{{end}} zur Organisation hinzugefügt{{end}}
+		{{- else if eq .Action "member_removed"}}{{with .Event.Membership}} hat {{name .User.Login}} aus der Organisation entfernt{{end}}
+		{{- else if eq .Action "member_invited"}}{{with .Event.Invitation}} hat {{if .Login}}{{name .Login}}{{else}}jemanden{{end}} in die Organisation eingeladen{{end}}
+		{{- else if eq .Action "renamed"}} hat die Organisation{{with .Event.Changes}}{{with .Login}} {{name .From}}{{end}}{{end}} umbenannt
+		{{- else}} hat die Organisation {{tr .Action}}
+		{{- end}} {{url .URL}}`,
+
+		"membership": `[{{repo .Repo}}] {{name .Actor}}
+		{{- if eq .Action "added"}} hat {{name .Event.Member.Login}} zum Team {{name .Event.Team.Name}} hinzugefügt
+		{{- else}} hat {{name .Event.Member.Login}} aus dem Team {{name .Event.Team.Name}} entfernt
+		{{- end}} {{url .URL}}`,
+
+		"installation": `{{with .Event.Installation}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- if eq $.Action "created"}} hat die App {{bold .AppSlug}}
+			{{- if eq .RepositorySelection "all"}} auf allen Repositories{{else}}{{with len $.Event.Repositories}} auf {{.}} {{plural . "Repository" "Repositories"}}{{end}}{{end}} installiert
+		{{- else if eq $.Action "deleted"}} hat die App {{bold .AppSlug}} deinstalliert
+		{{- else if eq $.Action "suspend"}} hat die App {{bold .AppSlug}} gesperrt
+		{{- else if eq $.Action "unsuspend"}} hat die App {{bold .AppSlug}} entsperrt
+		{{- else if eq $.Action "new_permissions_accepted"}} hat neue Berechtigungen für die App {{bold .AppSlug}} akzeptiert
+		{{- else}} hat die App {{bold .AppSlug}} {{tr $.Action}}
+		{{- end}} {{url $.URL}}{{end}}`,
+
+		"projects_v2_item": `[{{repo .Repo}}] {{name .Actor}}
+		{{- if eq .Action "created"}} hat {{template "projects_v2_item.kind" .}} zu {{template "projects_v2_item.project" .}} hinzugefügt
+		{{- else if eq .Action "deleted"}} hat {{template "projects_v2_item.kind" .}} aus {{template "projects_v2_item.project" .}} entfernt
+		{{- else if eq .Action "converted"}} hat einen Issue-Entwurf in {{template "projects_v2_item.project" .}} in ein Issue umgewandelt
+		{{- else if eq .Action "edited"}}{{with .Event.Changes.FieldValue}} hat für {{template "projects_v2_item.kind" $}} in {{template "projects_v2_item.project" $}} {{or .FieldName "ein Feld"}}
+			{{- with fieldValue .To}}{{$to := .}}{{with fieldValue $.Event.Changes.FieldValue.From}} von {{.}}{{end}} auf {{bold $to}}{{end}} geändert{{end}}
+		{{- else}} hat {{template "projects_v2_item.kind" .}} in {{template "projects_v2_item.project" .}} {{tr .Action}}
+		{{- end}} {{url .URL}}`,
+
+		// Always follows zu, aus or in, so it's in the dative.
+		"projects_v2_item.project": `{{with projectNumber .Event}}Projekt #{{.}}{{else}}einem Projekt{{end}}`,
+
+		"projects_v2_item.kind": `{{$t := .Event.ProjectsV2Item.ContentType}}
+		{{- if eq $t "Issue"}}ein Issue
+		{{- else if eq $t "PullRequest"}}einen Pull-Request
+		{{- else if eq $t "DraftIssue"}}einen Issue-Entwurf
+		{{- else}}ein Element
+		{{- end}}`,
+
+		"gollum": `[{{.Repo}}] {{.Actor}}
+		{{- if eq (len .Event.Pages) 1}}{{with index .Event.Pages 0}} hat die Wiki-Seite {{.Title}} {{tr .Action}}{{if .Summary}}: {{.Summary}}{{end}}{{end}}
+		{{- else}} hat Wiki-Seiten bearbeitet ({{toSentence (pageCounts .Event.Pages)}})
+		{{- end}} {{url .URL}}`,
+
+		"dependabot_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} hat Dependabot-Alarm #{{.Number}} {{tr (alertVerb $.Action)}}:
+		{{- with .SecurityAdvisory}}{{if .Severity}} [{{severity .Severity}}]{{end}}{{end}}
+		{{- with .Dependency}}{{if .Package.Name}} {{branch (packageName .Package)}}{{end}}{{end}}
+		{{- with .SecurityAdvisory}}{{if .Summary}}: {{firstLine .Summary}}{{end}}{{end}}
+		{{- if .State}} (jetzt {{tr .State}}){{end}} {{url $.URL}}{{end}}`,
+
+		"code_scanning_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} hat Code-Scanning-Alarm #{{.Number}} {{tr (alertVerb $.Action)}}:
+		{{- $tool := ""}}{{with .Tool}}{{if .Name}}{{$tool = printf "%s " .Name}}{{end}}{{end}}
+		{{- with .Rule}}
+			{{- with or .SecuritySeverityLevel .Severity}} [{{severity .}}]{{end}} {{branch (print $tool .ID)}}
+			{{- if .Description}}: {{firstLine .Description}}{{end}}
+		{{- end}}
+		{{- if .State}} (jetzt {{tr .State}}){{end}} {{url $.URL}}{{end}}`,
+
+		"secret_scanning_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} hat Secret-Scanning-Alarm #{{.Number}} {{tr (alertVerb $.Action)}}
+		{{- with or .SecretTypeDisplayName .SecretType}}: {{branch .}}{{end}}
+		{{- if .State}} (jetzt {{tr .State}}{{if .Resolution}} als {{tr .Resolution}}{{end}}){{end}} {{url $.URL}}{{end}}`,
+
+		"repository_advisory": `{{with .Event.RepositoryAdvisory}}[{{repo $.Repo}}] {{name $.Actor}} hat Sicherheitshinweis {{.GhsaID}}{{if .CveID}} ({{.CveID}}){{end}} {{tr (alertVerb $.Action)}}:
+		{{- if .Severity}} [{{severity .Severity}}]{{end}}
+		{{- if .Summary}} {{firstLine .Summary}}{{end}}
+		{{- if .State}} (jetzt {{tr .State}}){{end}} {{url $.URL}}{{end}}`,
+
+		"workflow_run": `{{with .Highlight}}{{.}}: {{end}}{{with .Event.WorkflowRun}}[{{repo $.Repo}}] {{.Name}}{{if .RunNumber}} #{{.RunNumber}}{{end}}
+		{{- if eq .Conclusion "timed_out"}} hat auf {{branch .HeadBranch}} {{warning "das Zeitlimit überschritten"}}{{else}} ist auf {{branch .HeadBranch}} {{warning "fehlgeschlagen"}}{{end}}
+		{{- range .PullRequests}} (Pull-Request #{{.Number}}){{end}}{{with .HeadSHA}} ({{hash (shortSHA .)}}){{end}} {{url $.URL}}{{end}}`,
+	},
+}
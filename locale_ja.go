@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Japanese has no plural forms, and the words are mostly the stems of
+// verbs that the templates finish with しました, like 編集 for "edited".
+var japaneseCatalog = &catalog{
+	plural: func(n int) int { return 0 },
+	toSentence: func(a []string) string {
+		return strings.Join(a, "、")
+	},
+	others: func(first string, n int) string {
+		return first + " ほか " + fmt_count(n) + " 人"
+	},
+	thousands: ",",
+	window: func(d time.Duration) string {
+		switch {
+		case d%time.Hour == 0:
+			return fmt.Sprintf("%d時間", d/time.Hour)
+		case d%time.Minute == 0:
+			return fmt.Sprintf("%d分", d/time.Minute)
+		default:
+			return d.String()
+		}
+	},
+	ago: func(n int, unit string) string {
+		return fmt.Sprintf("%d%s前", n, map[string]string{
+			"day":   "日",
+			"week":  "週間",
+			"month": "か月",
+			"year":  "年",
+		}[unit])
+	},
+
+	words: map[string]string{
+		// actions
+		"opened":             "オープン",
+		"closed":             "クローズ",
+		"reopened":           "再オープン",
+		"edited":             "編集",
+		"created":            "作成",
+		"deleted":            "削除",
+		"transferred":        "移管",
+		"pinned":             "ピン留め",
+		"unpinned":           "ピン留め解除",
+		"locked":             "ロック",
+		"unlocked":           "ロック解除",
+		"synchronize":        "更新",
+		"ready_for_review":   "レビュー可能に",
+		"converted_to_draft": "下書きに",
+		"review_requested":   "レビュー依頼",
+		"answered":           "回答",
+		"unanswered":         "未回答に",
+		"category_changed":   "カテゴリ変更",
+		"archived":           "アーカイブ",
+		"unarchived":         "アーカイブ解除",
+		"renamed":            "名前変更",
+		"restored":           "復元",
+		"reordered":          "並べ替え",
+		"cancelled":          "キャンセル",
+		"published":          "公開",
+		"reported":           "報告",
+		"withdrawn":          "取り下げ",
+		"updated":            "更新",
+		"dismissed":          "却下",
+		"fixed":              "修正",
+		"auto-dismissed":     "自動却下",
+		"auto-reopened":      "自動再オープン",
+		"resolved":           "解決",
+		"reintroduced":       "再検出",
+		"appeared in branch": "ブランチで検出",
+
+		// states
+		"open":           "オープン",
+		"auto_dismissed": "自動却下",
+		"draft":          "下書き",
+		"triage":         "トリアージ中",
+		"success":        "成功",
+		"failure":        "失敗",
+		"error":          "エラー",
+		"pending":        "保留中",
+		"in_progress":    "実行中",
+		"queued":         "待機中",
+		"inactive":       "非アクティブ",
+
+		// severities
+		"critical": "緊急",
+		"high":     "高",
+		"medium":   "中",
+		"moderate": "中",
+		"low":      "低",
+		"warning":  "警告",
+		"note":     "注意",
+
+		// resolutions of secret scanning alerts
+		"false_positive":  "誤検知",
+		"wont_fix":        "修正しない",
+		"revoked":         "失効",
+		"used_in_tests":   "テストで使用",
+		"pattern_edited":  "パターン編集",
+		"pattern_deleted": "パターン削除",
+	},
+
+	templates: map[string]string{
+		"push": `[{{repo .Repo}}] {{if .Event.Pusher.Name}}{{name .Actor}}{{else}}誰か{{end}} が
+		{{- $e := .Event}}{{$n := len .Commits}}
+		{{- if created $e}}
+			{{- if isTag $e}} {{if $e.BaseRef}}{{branch (baseRefName $e)}}{{else}}{{hash (shortSHA $e.After)}}{{end}} にタグ {{tag (refName $e)}} を付けました
+			{{- else}}
+				{{- if $e.BaseRef}} {{branch (baseRefName $e)}} から{{else if eq $n 0}} {{hash (shortSHA $e.After)}} に{{end}}ブランチ {{branch (refName $e)}} を作成しました
+				{{- ""}} (+{{bold (print $n)}} 件の新しいコミット)
+			{{- end}}
+		{{- else if deleted $e}} {{hash (shortSHA $e.Before)}} のブランチ {{branch (refName $e)}} を{{warning "削除"}}しました
+		{{- else if forced $e}} {{branch (refName $e)}} を {{hash (shortSHA $e.Before)}} から {{hash (shortSHA $e.After)}} に{{warning "強制プッシュ"}}しました
+		{{- else if and $e.Commits (eq $n 0)}}
+			{{- if $e.BaseRef}} {{branch (baseRefName $e)}} を {{branch (refName $e)}} にマージしました
+			{{- else}} {{branch (refName $e)}} を {{hash (shortSHA $e.Before)}} から {{hash (shortSHA $e.After)}} に早送りしました
+			{{- end}}
+		{{- else}} {{branch (refName $e)}} に {{bold (print $n)}} 件の新しいコミットをプッシュしました
+		{{- end}}: {{url .URL}}`,
+
+		"push.commit": `{{repo .Repo}}/{{branch (refName .Event)}} {{hash (shortSHA .Commit.ID)}} {{name .Commit.Author.Name}}
+		{{- $by := committer .Commit}}{{$age := authored .Commit}}
+		{{- if or $by $age}} ({{with $by}}コミット: {{name .}}{{if $age}}、{{end}}{{end}}{{with $age}}{{.}}に作成{{end}}){{end}}: {{firstLine .Commit.Message}}
+		{{- if .Files}} ({{.Files}} ファイル){{end}}`,
+
+		"push.more": `... ほか {{.Count}} 件の{{if .Newest}}古い{{end}}コミット
+		{{- if .URL}}: {{url .URL}}{{end}}`,
+
+		"commit_comment": `[{{repo .Repo}}] {{name .Actor}} がコミット {{hash (shortSHA .Event.Comment.CommitID)}} にコメントしました{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"pull_request": `{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} がプルリクエスト #{{.Number}} を{{tr $.Action}}しました: {{.Title}}
+		{{- ""}} ({{branch .Base.Ref}}...{{if eq .Head.Ref .Base.Ref}}{{branch .Head.Label}}{{else}}{{branch .Head.Ref}}{{end}})
+		{{- if eq $.Action "edited"}} {{template "edited" $}}{{end}} {{url $.URL}}{{end}}`,
+
+		"pull_request.merged": `{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} がプルリクエスト #{{.Number}}
+		{{- with $.Count}} ({{bold (print .)}} 件のコミット){{end}} を {{branch .Base.Ref}} にマージし
+		{{- if $.Deleted}}、{{branch .Head.Ref}} を{{warning "削除"}}し{{end}}ました: {{.Title}} {{url $.URL}}{{end}}`,
+
+		"pull_request_review": `{{with .Highlight}}{{.}}: {{end}}{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} がプルリクエスト #{{.Number}}
+		{{- $state := $.Event.Review.State}}
+		{{- if eq $state "approved"}} を承認しました
+		{{- else if eq $state "changes_requested"}} に変更を依頼しました
+		{{- else}} をレビューしました
+		{{- end}}{{with summarize $.Event.Review.Body}}: {{.}}{{end}} {{url $.URL}}{{end}}`,
+
+		"pull_request_review_comment": `[{{repo .Repo}}] {{name .Actor}} がプルリクエスト #{{.Event.PullRequest.Number}} {{hash (shortSHA .Event.Comment.CommitID)}}
+		{{- if eq .Action "edited"}} のコメントを編集しました {{template "edited" .}}{{else}} にコメントしました{{end}}
+		{{- with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"issues": `[{{repo .Repo}}] {{name .Actor}} が Issue #{{.Event.Issue.Number}} を{{tr .Action}}しました{{if eq .Action "edited"}} {{template "edited" .}}{{end}}: {{.Event.Issue.Title}} {{url .URL}}`,
+
+		"issues.triage": `[{{repo .Repo}}] {{name .Actor}} が Issue #{{.Event.Issue.Number}}
+		{{- $e := .Event}}{{$issue := .Event.Issue}}
+		{{- if eq .Action "labeled"}} にラベル {{with $e.Label}}{{label .Name .Color}}{{end}} を付けました
+		{{- else if eq .Action "unlabeled"}} からラベル {{with $e.Label}}{{label .Name .Color}}{{end}} を外しました
+		{{- else if eq .Action "assigned"}} を {{with $e.Assignee}}{{name .Login}}{{end}} に割り当てました
+		{{- else if eq .Action "unassigned"}} の {{with $e.Assignee}}{{name .Login}}{{end}} への割り当てを解除しました
+		{{- else if eq .Action "milestoned"}} をマイルストーン {{template "issues.milestone" .}} に追加しました
+		{{- else if eq .Action "demilestoned"}} をマイルストーン {{template "issues.milestone" .}} から外しました
+		{{- else if eq .Action "transferred"}} を
+			{{- with $e.Changes}}{{if .NewRepository}} {{repo .NewRepository.FullName}}{{with .NewIssue}}#{{.Number}}{{end}} に{{end}}{{end}}移管しました
+		{{- else}} を{{tr .Action}}しました
+		{{- end}}: {{$issue.Title}}
+		{{- if and $issue.Milestone (ne .Action "milestoned") (ne .Action "demilestoned")}} (マイルストーン {{bold $issue.Milestone.Title}}){{end}} {{url .URL}}`,
+
+		"issue_comment": `[{{repo .Repo}}] {{name .Actor}} が Issue #{{.Event.Issue.Number}}
+		{{- if eq .Action "edited"}} のコメントを編集しました {{template "edited" .}}{{else}} にコメントしました{{end}}
+		{{- with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"discussion": `{{with .Event.Discussion}}[{{repo $.Repo}}] {{name $.Actor}} がディスカッション #{{.Number}}
+		{{- if eq $.Action "created"}} を{{if .Category.Name}} {{.Category.Name}} で{{end}}開始しました
+		{{- else}} を{{tr $.Action}}しました
+		{{- end}}: {{.Title}} {{url $.URL}}{{end}}`,
+
+		"discussion_comment": `[{{repo .Repo}}] {{name .Actor}} がディスカッション #{{.Event.Discussion.Number}}
+		{{- if eq .Action "edited"}} のコメントを編集しました {{template "edited" .}}{{else}} にコメントしました{{end}}
+		{{- with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+		"edited": `(+{{.Added}}/-{{.Removed}} 行)`,
+
+		"deployment": `{{with .Event.Deployment}}[{{repo $.Repo}}] {{name $.Actor}} が {{branch .Ref}} ({{hash (shortSHA .SHA)}}) を {{environment $.Event}} にデプロイしています {{url $.URL}}{{end}}`,
+
+		"deployment_status": `{{with .Event.Deployment}}[{{repo $.Repo}}] {{name .Creator.Login}} による {{branch .Ref}} ({{hash (shortSHA .SHA)}}) の {{environment $.Event}} へのデプロイ
+		{{- end}}{{with .Event.DeploymentStatus}}: {{state .State}}{{if .Description}} - {{firstLine .Description}}{{end}} {{url $.URL}}{{end}}`,
+
+		"member": `[{{repo .Repo}}] {{name .Actor}} が
+		{{- $member := .Event.Member.Login}}
+		{{- if eq .Action "added"}} {{name $member}} をコラボレーターに追加しました
+		{{- else if eq .Action "removed"}} {{name $member}} をコラボレーターから外しました
+		{{- else}}コラボレーター {{name $member}} の権限を
+			{{- with .Event.Changes}}{{with .Permission}}{{if .To}} {{.From}} から {{.To}} に{{end}}{{end}}{{end}}変更しました
+		{{- end}} {{url .URL}}`,
+
+		"repository": `[{{repo .Repo}}] {{name .Actor}} が
+		{{- $c := .Event.Changes}}{{$from := formerOwner .Event}}
+		{{- if and (eq .Action "renamed") $c $c.Repository $c.Repository.Name}}リポジトリ名を {{repo $c.Repository.Name.From}} から {{repo .Event.Repository.Name}} に変更しました
+		{{- else if and (eq .Action "transferred") $from}}リポジトリを {{name $from}} から {{name .Event.Repository.Owner.Login}} に移管しました
+		{{- else if eq .Action "publicized"}}リポジトリを{{bold "公開"}}にしました
+		{{- else if eq .Action "privatized"}}リポジトリを{{bold "非公開"}}にしました
+		{{- else}}リポジトリを{{tr .Action}}しました
+		{{- end}} {{url .URL}}`,
+
+		"public": `[{{repo .Repo}}] {{name .Actor}} がリポジトリを{{bold "公開"}}にしました {{url .URL}}`,
+
+		"branch_protection_rule": `[{{repo .Repo}}] {{name .Actor}} が {{branch .Event.Rule.Name}} のブランチ保護ルールを{{tr .Action}}しました {{url .URL}}`,
+
+		"team_add": `[{{repo .Repo}}] {{name .Actor}} がチーム {{name .Event.Team.Name}} にリポジトリへのアクセス権を付与しました {{url .URL}}`,
+
+		"star": `[{{repo .Repo}}] {{name .Actor}} がリポジトリにスターを付けました (現在 {{count .Event.Repository.StargazersCount}} 個) {{url .URL}}`,
+
+		"star.burst": `[{{repo .Repo}}] {{window .Window}}で {{bold (printf "%+d" .Count)}} スター (現在 {{count .Event.Repository.StargazersCount}} 個) {{url .URL}}`,
+
+		"digest": `{{if eq .Action "weekly"}}先週{{else}}昨日{{end}}の {{repo .Repo}}
+		{{- $sep := ": "}}{{with .Digest}}{{$people := len .Authors}}
+		{{- with .Commits}}{{$sep}}コミット {{bold (print .)}} 件 (作成者 {{$people}} 人){{$sep = "、"}}{{end}}
+		{{- with .PullsMerged}}{{$sep}}PR マージ {{.}} 件{{$sep = "、"}}{{end}}
+		{{- with .PullsOpened}}{{$sep}}PR オープン {{.}} 件{{$sep = "、"}}{{end}}
+		{{- with .IssuesOpened}}{{$sep}}Issue オープン {{.}} 件{{$sep = "、"}}{{end}}
+		{{- with .IssuesClosed}}{{$sep}}Issue クローズ {{.}} 件{{$sep = "、"}}{{end}}
+		{{- with .Releases}}{{$sep}}リリース {{.}} 件{{$sep = "、"}}{{end}}
+		{{- end}}`,
+
+		"sponsorship": `[{{repo .Repo}}] {{template "sponsorship.sponsor" .}} が {{name .Repo}}
+		{{- if eq .Action "created"}} のスポンサーになりました{{template "sponsorship.tier" .}}
+		{{- else if eq .Action "tier_changed"}} へのスポンサーシップを変更しました{{template "sponsorship.tier" .}}
+		{{- else}} へのスポンサーシップを{{tr .Action}}しました
+		{{- end}} {{url .URL}}`,
+
+		"sponsorship.sponsor": `{{if eq .Event.Sponsorship.PrivacyLevel "private"}}非公開のスポンサー{{else}}{{name .Actor}}{{end}}`,
+
+		"sponsorship.tier": `{{with .Event.Sponsorship.Tier}}{{if .Name}} ({{.Name}}){{else if gt .MonthlyPriceInDollars 0}} (月額 ${{.MonthlyPriceInDollars}}){{end}}{{end}}`,
+
+		"milestone": `{{with .Event.Milestone}}[{{repo $.Repo}}] {{name $.Actor}} がマイルストーン {{bold .Title}} を{{tr $.Action}}しました
+		{{- if and (ge (len .DueOn) 10) (ne $.Action "closed") (ne $.Action "deleted")}} (期限 {{slice .DueOn 0 10}}){{end}} {{url $.URL}}{{end}}`,
+
+		"label": `{{with .Event.Label}}[{{repo $.Repo}}] {{name $.Actor}} がラベル
+		{{- if eq $.Action "edited"}} {{$.Event.Changes.Name.From}} の名前を {{label .Name .Color}} に変更しました
+		{{- else}} {{label .Name .Color}} を{{tr $.Action}}しました
+		{{- end}} {{url $.URL}}{{end}}`,
+
+		"organization": `[{{repo .Repo}}] {{name .Actor}} が
+		{{- if eq .Action "member_added"}}{{with .Event.Membership}} {{name .User.Login}} を組織に{{if eq .Role "admin"}}{{bold "オーナー"}}として{{end}}追加しました{{end}}
+		{{- else if eq .Action "member_removed"}}{{with .Event.Membership}} {{name .User.Login}} を組織から外しました{{end}}
+		{{- else if eq .Action "member_invited"}}{{with .Event.Invitation}} {{if .Login}}{{name .Login}} {{else}}誰か{{end}}を組織に招待しました{{end}}
+		{{- else if eq .Action "renamed"}}組織名を{{with .Event.Changes}}{{with .Login}} {{name .From}} から{{end}}{{end}}変更しました
+		{{- else}}組織を{{tr .Action}}しました
+		{{- end}} {{url .URL}}`,
+
+		"membership": `[{{repo .Repo}}] {{name .Actor}} が {{name .Event.Member.Login}} をチーム {{name .Event.Team.Name}}
+		{{- if eq .Action "added"}} に追加しました
+		{{- else}} から外しました
+		{{- end}} {{url .URL}}`,
+
+		"installation": `{{with .Event.Installation}}[{{repo $.Repo}}] {{name $.Actor}} が {{bold .AppSlug}} アプリ
+		{{- if eq $.Action "created"}}を{{if eq .RepositorySelection "all"}}すべてのリポジトリに{{else}}{{with len $.Event.Repositories}} {{.}} 個のリポジトリに{{end}}{{end}}インストールしました
+		{{- else if eq $.Action "deleted"}}をアンインストールしました
+		{{- else if eq $.Action "suspend"}}を一時停止しました
+		{{- else if eq $.Action "unsuspend"}}の一時停止を解除しました
+		{{- else if eq $.Action "new_permissions_accepted"}}の新しい権限を承認しました
+		{{- else}}を{{tr $.Action}}しました
+		{{- end}} {{url $.URL}}{{end}}`,
+
+		"projects_v2_item": `[{{repo .Repo}}] {{name .Actor}} が
+		{{- if eq .Action "created"}} {{template "projects_v2_item.kind" .}} を {{template "projects_v2_item.project" .}} に追加しました
+		{{- else if eq .Action "deleted"}} {{template "projects_v2_item.kind" .}} を {{template "projects_v2_item.project" .}} から削除しました
+		{{- else if eq .Action "converted"}} {{template "projects_v2_item.project" .}} の下書き Issue を Issue に変換しました
+		{{- else if eq .Action "edited"}}{{with .Event.Changes.FieldValue}} {{template "projects_v2_item.project" $}} の {{template "projects_v2_item.kind" $}} の {{or .FieldName "フィールド"}} を
+			{{- with fieldValue .To}}{{$to := .}}{{with fieldValue $.Event.Changes.FieldValue.From}} {{.}} から{{end}} {{bold $to}} に{{end}}変更しました{{end}}
+		{{- else}} {{template "projects_v2_item.project" .}} の {{template "projects_v2_item.kind" .}} を{{tr .Action}}しました
+		{{- end}} {{url .URL}}`,
+
+		"projects_v2_item.kind": `{{$t := .Event.ProjectsV2Item.ContentType}}
+		{{- if eq $t "Issue"}}Issue
+		{{- else if eq $t "PullRequest"}}プルリクエスト
+		{{- else if eq $t "DraftIssue"}}下書き Issue
+		{{- else}}アイテム
+		{{- end}}`,
+
+		"projects_v2_item.project": `{{with projectNumber .Event}}プロジェクト #{{.}}{{else}}プロジェクト{{end}}`,
+
+		"gollum": `[{{.Repo}}] {{.Actor}} が
+		{{- if eq (len .Event.Pages) 1}}{{with index .Event.Pages 0}} Wiki ページ {{.Title}} を{{tr .Action}}しました{{if .Summary}}: {{.Summary}}{{end}}{{end}}
+		{{- else}} Wiki ページを更新しました ({{toSentence (pageCounts .Event.Pages)}})
+		{{- end}} {{url .URL}}`,
+
+		"dependabot_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} が Dependabot アラート #{{.Number}} を{{tr (alertVerb $.Action)}}しました:
+		{{- with .SecurityAdvisory}}{{if .Severity}} [{{severity .Severity}}]{{end}}{{end}}
+		{{- with .Dependency}}{{if .Package.Name}} {{branch (packageName .Package)}}{{end}}{{end}}
+		{{- with .SecurityAdvisory}}{{if .Summary}}: {{firstLine .Summary}}{{end}}{{end}}
+		{{- if .State}} (現在: {{tr .State}}){{end}} {{url $.URL}}{{end}}`,
+
+		"code_scanning_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} がコードスキャンアラート #{{.Number}} を{{tr (alertVerb $.Action)}}しました:
+		{{- $tool := ""}}{{with .Tool}}{{if .Name}}{{$tool = printf "%s " .Name}}{{end}}{{end}}
+		{{- with .Rule}}
+			{{- with or .SecuritySeverityLevel .Severity}} [{{severity .}}]{{end}} {{branch (print $tool .ID)}}
+			{{- if .Description}}: {{firstLine .Description}}{{end}}
+		{{- end}}
+		{{- if .State}} (現在: {{tr .State}}){{end}} {{url $.URL}}{{end}}`,
+
+		"secret_scanning_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} がシークレットスキャンアラート #{{.Number}} を{{tr (alertVerb $.Action)}}しました
+		{{- with or .SecretTypeDisplayName .SecretType}}: {{branch .}}{{end}}
+		{{- if .State}} (現在: {{tr .State}}{{if .Resolution}}、{{tr .Resolution}}{{end}}){{end}} {{url $.URL}}{{end}}`,
+
+		"repository_advisory": `{{with .Event.RepositoryAdvisory}}[{{repo $.Repo}}] {{name $.Actor}} がセキュリティアドバイザリ {{.GhsaID}}{{if .CveID}} ({{.CveID}}){{end}} を{{tr (alertVerb $.Action)}}しました:
+		{{- if .Severity}} [{{severity .Severity}}]{{end}}
+		{{- if .Summary}} {{firstLine .Summary}}{{end}}
+		{{- if .State}} (現在: {{tr .State}}){{end}} {{url $.URL}}{{end}}`,
+
+		"workflow_run": `{{with .Highlight}}{{.}}: {{end}}{{with .Event.WorkflowRun}}[{{repo $.Repo}}] {{.Name}}{{if .RunNumber}} #{{.RunNumber}}{{end}} が {{branch .HeadBranch}} で
+		{{- if eq .Conclusion "timed_out"}}{{warning "タイムアウトしました"}}{{else}}{{warning "失敗しました"}}{{end}}
+		{{- range .PullRequests}} (プルリクエスト #{{.Number}}){{end}}{{with .HeadSHA}} ({{hash (shortSHA .)}}){{end}} {{url $.URL}}{{end}}`,
+	},
+}
@@ -9,9 +9,11 @@ import (
 	"io/ioutil"
 	"log"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
 )
 
@@ -25,6 +27,7 @@ var botLog = log.New(os.Stderr, "[bot] ", log.LstdFlags)
 func main() {
 	ircUrl := flag.String("irc", "", "irc server url (including nick and channel)")
 	debugFlag := flag.Bool("debug", false, "print debug logs")
+	configFile := flag.String("config", "", "config `file` with additional destinations")
 	flag.Parse()
 
 	if *ircUrl == "" {
@@ -32,6 +35,15 @@ func main() {
 		return
 	}
 
+	cfg := new(Config)
+	if *configFile != "" {
+		var err error
+		cfg, err = loadConfig(*configFile)
+		if err != nil {
+			log.Fatalln("error reading config:", err)
+		}
+	}
+
 	// Read server secret
 	secret, err := ioutil.ReadFile(secretFile)
 	if err != nil {
@@ -52,10 +64,10 @@ func main() {
 		log.Fatalf("error: server secret is not the expected size; want %d found %d", secretSizeBase64, len(secret))
 	}
 
-	run(secret, *ircUrl, *debugFlag)
+	run(secret, *ircUrl, cfg, *debugFlag)
 }
 
-func run(secret []byte, ircUrl string, debug bool) {
+func run(secret []byte, ircUrl string, cfg *Config, debug bool) {
 	l, err := listen()
 	if err != nil {
 		log.Fatal(err)
@@ -69,9 +81,25 @@ func run(secret []byte, ircUrl string, debug bool) {
 		irc.SetLogger(log.New(os.Stderr, "[irc] ", log.LstdFlags))
 	}
 
+	if err := cfg.check(irc.Channel()); err != nil {
+		log.Fatalln("error in config:", err)
+	}
+	if cfg.Shortener != nil {
+		urlShortener, err = cfg.Shortener.open()
+		if err != nil {
+			log.Fatalln("error setting up shortener:", err)
+		}
+	}
+	ircNicks = cfg.Nicks
+	dests := cfg.destinations(irc.Channel())
+	for _, d := range dests {
+		irc.AddChannel(d.Channel)
+	}
+
 	type githubEvent struct {
-		Type string
-		Body []byte
+		Type     string
+		Delivery string
+		Body     []byte
 	}
 
 	events := make(chan githubEvent, 10)
@@ -79,24 +107,67 @@ func run(secret []byte, ircUrl string, debug bool) {
 	h := &Webhook{
 		Root:   "/webhook",
 		Logger: log.New(os.Stderr, "[webhook] ", log.LstdFlags),
-		Handler: func(event string, body []byte) {
+		Handler: func(event string, delivery string, body []byte) {
 			select {
-			case events <- githubEvent{Type: event, Body: body}:
+			case events <- githubEvent{Type: event, Delivery: delivery, Body: body}:
 			default:
 			}
 		},
 		Secret: secret,
 	}
 
+	stars := &StarCounter{
+		Window: *starWindow,
+		Announce: func(d *Destination, msg string) {
+			if err := irc.AnnounceTo(d.Channel, msg); err != nil {
+				botLog.Printf("error sending message for stars to %s: %v", d.Channel, err)
+			}
+		},
+	}
+
+	digests := &Digester{
+		Filename: *digestFile,
+		Announce: func(d *Destination, msg string) {
+			if err := irc.AnnounceTo(d.Channel, msg); err != nil {
+				botLog.Printf("error sending digest to %s: %v", d.Channel, err)
+			}
+		},
+	}
+	if err := digests.Start(dests); err != nil {
+		log.Fatalln("error loading digests:", err)
+	}
+
+	// Bursts are handed back to the main loop to announce,
+	// so that they don't get mixed up with events announced in the meantime.
+	burstEvents := make(chan []BurstEvent)
+	bursts := &BurstCoalescer{
+		Window: *burstWindow,
+		Report: func(events []BurstEvent) {
+			burstEvents <- events
+		},
+	}
+
 	// main loop
 	go func() {
-		for event := range events {
-			reportEvent(irc, event.Type, event.Body)
+		for {
+			select {
+			case event := <-events:
+				reportEvent(irc, dests, stars, digests, bursts, event.Type, event.Delivery, event.Body)
+			case burst := <-burstEvents:
+				announceEvents(irc, dests, stars, digests, burst)
+			}
 		}
 	}()
 
+	var handler http.Handler = h
+	if links, ok := urlShortener.(*LinkShortener); ok {
+		mux := http.NewServeMux()
+		mux.Handle("/s/", links)
+		mux.Handle("/", h)
+		handler = mux
+	}
 	go func() {
-		log.Fatal(h.Serve(l))
+		log.Fatal(serveHTTP(l, handler, h.Logger))
 	}()
 
 	if err := irc.Run(); err != nil {
@@ -106,26 +177,81 @@ func run(secret []byte, ircUrl string, debug bool) {
 	}
 }
 
-func reportEvent(irc *IRC, eventType string, body []byte) {
+func reportEvent(irc *IRC, dests []*Destination, stars *StarCounter, digests *Digester, bursts *BurstCoalescer, eventType string, delivery string, body []byte) {
+	// A malformed payload shouldn't take down the whole bot
+	defer func() {
+		if err := recover(); err != nil {
+			botLog.Printf("panic while reporting %s event (delivery %s): %v\n%s", eventType, delivery, err, debug.Stack())
+			botLog.Printf("payload body: %q", body)
+		}
+	}()
+
 	gh, err := ParseGithubEvent(body)
 	if err != nil {
 		botLog.Printf("error parsing %s event: %v", eventType, err)
 		botLog.Printf("payload body: %q", body)
 		return
 	}
-	msg := FormatGithubEvent(eventType, gh, nil)
-	if msg == "" {
-		repo := "unknown repo"
-		if gh.Repository.FullName != "" {
-			repo = gh.Repository.FullName
-		}
-		botLog.Printf("ignoring %s event for %s", eventType, repo)
+	pullRequestAuthors.record(eventType, gh)
+	if bursts.Add(eventType, gh) {
 		return
 	}
-	err = irc.Announce(msg)
-	if err != nil {
-		botLog.Printf("error sending message for %s event: %v", eventType, err)
-		return
+	announceEvents(irc, dests, stars, digests, []BurstEvent{{eventType, gh}})
+}
+
+// Announce events for a repository to the destinations that want them.
+func announceEvents(irc *IRC, dests []*Destination, stars *StarCounter, digests *Digester, events []BurstEvent) {
+	defer func() {
+		if err := recover(); err != nil {
+			botLog.Printf("panic while reporting %d events: %v\n%s", len(events), err, debug.Stack())
+		}
+	}()
+
+	announced := make([]bool, len(events))
+	for _, d := range dests {
+		var wanted []BurstEvent
+		var indexes []int
+		for i, e := range events {
+			if !d.wants(e.Type) {
+				continue
+			}
+			ok, rule := FilterGithubEvent(e.Type, e.Event, d.Rules)
+			if *explain {
+				botLog.Printf("%s event for %s: %s", e.Type, d.Channel, explainFilter(d.Rules, ok, rule))
+			}
+			if !ok {
+				continue
+			}
+			if digests.Add(d, e.Type, e.Event) || stars.Add(d, e.Type, e.Event) {
+				announced[i] = true
+				continue
+			}
+			wanted = append(wanted, e)
+			indexes = append(indexes, i)
+		}
+		msgs := FormatGithubEvents(wanted, d.Options)
+		if len(msgs) == 0 {
+			continue
+		}
+		// Related events are combined, so there's no telling which ones made it in.
+		for _, i := range indexes {
+			announced[i] = true
+		}
+		for _, msg := range msgs {
+			if err := irc.AnnounceTo(d.Channel, msg); err != nil {
+				botLog.Printf("error sending message to %s: %v", d.Channel, err)
+			}
+		}
+	}
+	for i, e := range events {
+		if announced[i] {
+			continue
+		}
+		repo := "unknown repo"
+		if e.Event.Repository.FullName != "" {
+			repo = e.Event.Repository.FullName
+		}
+		botLog.Printf("ignoring %s event for %s", e.Type, repo)
 	}
 }
 
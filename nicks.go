@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+// The IRC nicks of GitHub users, by login.
+// See Config.Nicks.
+var ircNicks map[string]string
+
+// The name to show for a GitHub login.
+func (data *EventFormatterOptions) nick(login string) string {
+	name := login
+	if nick, ok := ircNicks[login]; ok {
+		name = nick
+	}
+	if data.NoHighlights {
+		name = unhighlight(name)
+	}
+	return name
+}
+
+// The nick to highlight about something that happened to a pull request,
+// if HighlightAuthors is set and the author has a nick.
+// People aren't highlighted about things they did themselves.
+func (data *EventFormatterOptions) highlight(author, sender string) string {
+	if !data.HighlightAuthors || author == "" || author == sender {
+		return ""
+	}
+	return ircNicks[author]
+}
+
+// The authors of open pull requests, by repository and number,
+// as seen in pull_request events.
+// Workflow runs say which pull requests they're for, but not who opened them.
+var pullRequestAuthors = &authorLog{authors: make(map[string]string)}
+
+type authorLog struct {
+	mu      sync.Mutex
+	authors map[string]string
+}
+
+func pullRequestKey(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repo, number)
+}
+
+// Remember who opened the pull request an event is about,
+// or forget them once it's closed.
+func (l *authorLog) record(eventType string, event *GHEvent) {
+	pr := event.PullRequest
+	if eventType != "pull_request" || pr == nil || pr.User.Login == "" {
+		return
+	}
+	key := pullRequestKey(event.Repository.FullName, pr.Number)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if event.Action == "closed" {
+		delete(l.authors, key)
+		return
+	}
+	l.authors[key] = pr.User.Login
+}
+
+// The author of a pull request, if it's been seen.
+func (l *authorLog) author(repo string, number int) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.authors[pullRequestKey(repo, number)]
+}
+
+// Put a zero-width space after the first letter of a name,
+// so that IRC clients don't take it for the nick of someone in the channel.
+func unhighlight(name string) string {
+	_, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return name
+	}
+	return name[:size] + "\u200b" + name[size:]
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// A failed CI run highlights the author of the pull request,
+// not whoever re-ran or merged it.
+func TestWorkflowRunHighlight(t *testing.T) {
+	defer func(nicks map[string]string) { ircNicks = nicks }(ircNicks)
+	ircNicks = map[string]string{"carol": "carol_", "dave": "dave_"}
+
+	const repo = `"repository":{"name":"app","full_name":"acme/app"}`
+	run := parseEvent(t, `{"action":"completed","workflow_run":{"name":"CI","conclusion":"failure","head_branch":"fix","actor":{"login":"dave"},"pull_requests":[{"number":12}]},"sender":{"login":"dave"},`+repo+`}`)
+	cfg := &EventFormatterOptions{LongURL: true, NoColors: true, HighlightAuthors: true}
+
+	if msg := FormatGithubEvent("workflow_run", run, cfg); msg == "" || strings.Contains(msg, "dave_:") {
+		t.Errorf("unknown author: got %q, want no highlight", msg)
+	}
+
+	opened := parseEvent(t, `{"action":"opened","pull_request":{"number":12,"user":{"login":"carol"}},`+repo+`}`)
+	pullRequestAuthors.record("pull_request", opened)
+	if msg := FormatGithubEvent("workflow_run", run, cfg); !strings.HasPrefix(msg, "carol_: ") {
+		t.Errorf("got %q, want carol highlighted", msg)
+	}
+
+	closed := parseEvent(t, `{"action":"closed","pull_request":{"number":12,"user":{"login":"carol"}},`+repo+`}`)
+	pullRequestAuthors.record("pull_request", closed)
+	if author := pullRequestAuthors.author("acme/app", 12); author != "" {
+		t.Errorf("closed pull request: author is still %q", author)
+	}
+}
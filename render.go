@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"text/template/parse"
+)
+
+// A Renderer marks up messages for a particular kind of output.
+// The templates say what a message says;
+// the renderer decides what the parts of it look like.
+type Renderer interface {
+	// Escape plain text, so that it comes out as is.
+	Text(s string) string
+
+	// Mark up plain text as an element of a message:
+	// "repo", "name", "branch", "tag", "hash", "url", "bold", "warning",
+	// a severity ("critical", "high", "medium", "low"),
+	// or a state ("success", "failure", "pending", "inactive").
+	// Other elements are left unstyled.
+	Style(element, s string) string
+
+	// Mark up plain text in a hex RGB color, like "d73a4a".
+	Color(s, hex string) string
+
+	// Put together the lines of a message.
+	Finish(m *Message, lines []string) string
+}
+
+var renderers = map[string]Renderer{
+	"irc":      ircRenderer{},
+	"plain":    plainRenderer{},
+	"markdown": markdownRenderer{},
+	"html":     htmlRenderer{},
+	"json":     jsonRenderer{},
+}
+
+// The renderer for the configured Format.
+func (data *EventFormatterOptions) renderer() Renderer {
+	if r, ok := renderers[data.Format]; ok && data.Format != "irc" {
+		return r
+	}
+	if data.NoColors && data.Format == "" {
+		return plainRenderer{}
+	}
+	return data.ircRenderer()
+}
+
+// Output from the style functions, which is passed through as is.
+// Everything else a template prints is escaped by the renderer.
+type styled string
+
+// Pass the output of every action in a template through _escape,
+// like html/template does, so that text from the payload
+// can't be mistaken for markup.
+func escapeActions(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			escapeActions(c)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return // no output
+		}
+		escape := parse.NewIdentifier("_escape").SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{escape}})
+	case *parse.IfNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.RangeNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.WithNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	}
+}
+
+// Mark up a value with the renderer, unless it has been already.
+func markup(r Renderer, element string, v interface{}) styled {
+	if s, ok := v.(styled); ok {
+		return s
+	}
+	return styled(r.Style(element, fmt.Sprint(v)))
+}
+
+// Plain text, without any formatting.
+// Formatting codes in the payload are stripped too.
+type plainRenderer struct{}
+
+func (plainRenderer) Text(s string) string             { return colorRE.ReplaceAllString(s, "") }
+func (r plainRenderer) Style(element, s string) string { return r.Text(s) }
+func (r plainRenderer) Color(s, hex string) string     { return r.Text(s) }
+
+func (plainRenderer) Finish(m *Message, lines []string) string {
+	return strings.Join(lines, "\n")
+}
+
+// Markdown, as understood by Matrix and Slack.
+type markdownRenderer struct{}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+	`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `~`, `\~`,
+)
+
+func (markdownRenderer) Text(s string) string { return markdownEscaper.Replace(s) }
+
+func (r markdownRenderer) Style(element, s string) string {
+	if s == "" {
+		return ""
+	}
+	switch element {
+	case "url":
+		return "<" + s + ">"
+	case "branch", "tag", "hash":
+		if strings.Contains(s, "`") {
+			return "`` " + s + " ``"
+		}
+		return "`" + s + "`"
+	case "repo", "bold", "warning", "critical", "high", "failure":
+		return "**" + r.Text(s) + "**"
+	default:
+		return r.Text(s)
+	}
+}
+
+func (r markdownRenderer) Color(s, hex string) string { return r.Text(s) }
+
+func (markdownRenderer) Finish(m *Message, lines []string) string {
+	return strings.Join(lines, "  \n")
+}
+
+// HTML, as understood by Matrix.
+type htmlRenderer struct{}
+
+func (htmlRenderer) Text(s string) string { return html.EscapeString(s) }
+
+func (r htmlRenderer) Style(element, s string) string {
+	s = r.Text(s)
+	switch element {
+	case "url":
+		return `<a href="` + s + `">` + s + `</a>`
+	case "repo", "bold":
+		return "<b>" + s + "</b>"
+	case "branch", "tag", "hash":
+		return "<code>" + s + "</code>"
+	case "critical":
+		return `<b><font color="#ff0000">` + s + `</font></b>`
+	case "warning", "high", "failure":
+		return `<font color="#ff0000">` + s + `</font>`
+	case "medium", "pending":
+		return `<font color="#fc7f00">` + s + `</font>`
+	case "low":
+		return `<font color="#009393">` + s + `</font>`
+	case "success":
+		return `<font color="#009300">` + s + `</font>`
+	case "inactive":
+		return `<font color="#7f7f7f">` + s + `</font>`
+	default:
+		return s
+	}
+}
+
+func (r htmlRenderer) Color(s, hex string) string {
+	if _, ok := nearestColor(hex); !ok {
+		return r.Text(s)
+	}
+	return `<font color="#` + hex + `">` + r.Text(s) + `</font>`
+}
+
+func (htmlRenderer) Finish(m *Message, lines []string) string {
+	return strings.Join(lines, "<br>\n")
+}
+
+// A JSON object per message, with the plain text of the message
+// alongside the parts it was made from.
+type jsonRenderer struct {
+	plainRenderer
+}
+
+type jsonMessage struct {
+	Type    string       `json:"type"`
+	Repo    string       `json:"repo"`
+	Actor   string       `json:"actor"`
+	Action  string       `json:"action,omitempty"`
+	URL     string       `json:"url,omitempty"`
+	Text    string       `json:"text"`
+	Details []string     `json:"details,omitempty"`
+	Commits []jsonCommit `json:"commits,omitempty"`
+}
+
+type jsonCommit struct {
+	ID      string `json:"id"`
+	Author  string `json:"author"`
+	Message string `json:"message"`
+	URL     string `json:"url,omitempty"`
+}
+
+func (jsonRenderer) Finish(m *Message, lines []string) string {
+	out := jsonMessage{
+		Type:   m.Type,
+		Repo:   m.Repo,
+		Actor:  m.Actor,
+		Action: m.Action,
+		URL:    m.URL,
+		Text:   lines[0],
+	}
+	if len(lines) > 1 {
+		out.Details = lines[1:]
+	}
+	for _, c := range m.Commits {
+		out.Commits = append(out.Commits, jsonCommit{c.ID, c.Author.Name, c.Message, c.URL})
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	urlpkg "net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Shortener turns long URLs into short ones.
+type Shortener interface {
+	Shorten(url string) (string, error)
+}
+
+// The shortener used for destinations which don't set LongURL.
+// See Config.Shortener.
+var urlShortener Shortener = noShortener{}
+
+// ShortenerConfig says how to shorten URLs.
+type ShortenerConfig struct {
+	// "none" (the default) to leave URLs alone;
+	// "post" to POST the URL to a service and read the Location header of the response, like git.io did;
+	// "yourls" for a YOURLS server;
+	// or "builtin" to serve short links from the webhook's own HTTP listener.
+	Type string
+
+	// post, yourls: the URL of the API.
+	URL string
+	// post: the name of the form field to send the URL in (default "url").
+	Field string
+	// yourls: the secret signature token.
+	Signature string
+
+	// builtin: the URL where the webhook's listener can be reached,
+	// like "https://example.com"; links look like https://example.com/s/k3x9q0ab.
+	BaseURL string
+	// builtin: a file to keep the links in, so they survive restarts.
+	File string
+}
+
+func (c *ShortenerConfig) open() (Shortener, error) {
+	switch c.Type {
+	case "", "none":
+		return noShortener{}, nil
+	case "post":
+		if c.URL == "" {
+			return nil, errors.New("post shortener needs a URL")
+		}
+		field := c.Field
+		if field == "" {
+			field = "url"
+		}
+		return &cachedShortener{Shortener: &postShortener{c.URL, field}}, nil
+	case "yourls":
+		if c.URL == "" {
+			return nil, errors.New("yourls shortener needs a URL")
+		}
+		return &cachedShortener{Shortener: &yourlsShortener{c.URL, c.Signature}}, nil
+	case "builtin":
+		if c.BaseURL == "" {
+			return nil, errors.New("builtin shortener needs a BaseURL")
+		}
+		return openLinkShortener(c.BaseURL, c.File)
+	default:
+		return nil, fmt.Errorf("unknown shortener type %q", c.Type)
+	}
+}
+
+// Shortens a URL with urlShortener.
+// If that fails, the URL is returned as is.
+func shorten_url(url string) string {
+	short, err := urlShortener.Shorten(url)
+	if err != nil {
+		botLog.Printf("error shortening %s: %v", url, err)
+		return url
+	}
+	return short
+}
+
+var shortenerClient = &http.Client{
+	Timeout: 5 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+type noShortener struct{}
+
+func (noShortener) Shorten(url string) (string, error) { return url, nil }
+
+// Remembers the links another shortener made,
+// so that a URL always gets the same short link
+// and the service is only asked once.
+type cachedShortener struct {
+	Shortener
+
+	mu    sync.Mutex
+	cache map[string]string
+	urls  []string // in the order they were cached
+}
+
+// The most links to remember.
+// After that, the oldest ones are forgotten.
+const maxCachedLinks = 10000
+
+func (c *cachedShortener) Shorten(url string) (string, error) {
+	c.mu.Lock()
+	short, ok := c.cache[url]
+	c.mu.Unlock()
+	if ok {
+		return short, nil
+	}
+	short, err := c.Shortener.Shorten(url)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	if c.cache == nil {
+		c.cache = make(map[string]string)
+	}
+	if _, ok := c.cache[url]; !ok {
+		if len(c.urls) >= maxCachedLinks {
+			delete(c.cache, c.urls[0])
+			c.urls = c.urls[1:]
+		}
+		c.urls = append(c.urls, url)
+	}
+	c.cache[url] = short
+	c.mu.Unlock()
+	return short, nil
+}
+
+// POSTs the URL as a form and reads the short link from the Location header,
+// or from the body of the response if there is no Location.
+type postShortener struct {
+	endpoint string
+	field    string
+}
+
+func (s *postShortener) Shorten(url string) (string, error) {
+	resp, err := shortenerClient.PostForm(s.endpoint, urlpkg.Values{s.field: {url}})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("%s: %s", s.endpoint, resp.Status)
+	}
+	if loc := resp.Header.Get("Location"); loc != "" {
+		return loc, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 2048))
+	if err != nil {
+		return "", err
+	}
+	short := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(short, "http://") && !strings.HasPrefix(short, "https://") {
+		return "", fmt.Errorf("%s: no link in response", s.endpoint)
+	}
+	return short, nil
+}
+
+// Talks to the API of a YOURLS server.
+// https://yourls.org/docs/guide/advanced/api
+type yourlsShortener struct {
+	endpoint  string
+	signature string
+}
+
+func (s *yourlsShortener) Shorten(url string) (string, error) {
+	form := urlpkg.Values{
+		"action": {"shorturl"},
+		"format": {"json"},
+		"url":    {url},
+	}
+	if s.signature != "" {
+		form.Set("signature", s.signature)
+	}
+	resp, err := shortenerClient.PostForm(s.endpoint, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	// YOURLS reports a URL it has seen before as an error,
+	// but still includes the short link.
+	var result struct {
+		ShortURL string
+		Message  string
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return "", fmt.Errorf("%s: %s: %v", s.endpoint, resp.Status, err)
+	}
+	if result.ShortURL == "" {
+		return "", fmt.Errorf("%s: %s", s.endpoint, result.Message)
+	}
+	return result.ShortURL, nil
+}
+
+// A LinkShortener makes short links of its own,
+// and serves them from the webhook's HTTP listener under /s/.
+//
+// Links get random IDs, so that they can't be guessed,
+// and are kept in a file with one ID and URL per line.
+type LinkShortener struct {
+	BaseURL string
+
+	mu    sync.Mutex
+	file  *os.File
+	links map[string]string // URLs by ID
+	ids   map[string]string // IDs by URL
+}
+
+func openLinkShortener(baseURL, filename string) (*LinkShortener, error) {
+	s := &LinkShortener{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		links:   make(map[string]string),
+		ids:     make(map[string]string),
+	}
+	if filename == "" {
+		return s, nil
+	}
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.IndexByte(line, ' ')
+		if i <= 0 {
+			f.Close()
+			return nil, fmt.Errorf("reading %s: bad line %q", filename, line)
+		}
+		s.add(line[:i], line[i+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading %s: %v", filename, err)
+	}
+	s.file = f
+	return s, nil
+}
+
+func (s *LinkShortener) add(id, url string) {
+	s.links[id] = url
+	if _, ok := s.ids[url]; !ok {
+		s.ids[url] = id
+	}
+}
+
+const linkIDChars = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// A random ID for a new link which isn't taken yet.
+func (s *LinkShortener) newID() (string, error) {
+	var b [8]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", err
+		}
+		for i := range b {
+			b[i] = linkIDChars[int(b[i])%len(linkIDChars)]
+		}
+		if _, taken := s.links[string(b[:])]; !taken {
+			return string(b[:]), nil
+		}
+	}
+}
+
+func (s *LinkShortener) Shorten(url string) (string, error) {
+	if strings.ContainsAny(url, " \r\n") {
+		return "", errors.New("bad url")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.ids[url]
+	if !ok {
+		var err error
+		id, err = s.newID()
+		if err != nil {
+			return "", err
+		}
+		if s.file != nil {
+			if _, err := fmt.Fprintln(s.file, id, url); err != nil {
+				return "", err
+			}
+		}
+		s.add(id, url)
+	}
+	return s.BaseURL + "/s/" + id, nil
+}
+
+// Redirects /s/<id> to the link's URL.
+func (s *LinkShortener) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	url, ok := s.links[strings.TrimPrefix(req.URL.Path, "/s/")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	http.Redirect(w, req, url, http.StatusMovedPermanently)
+}
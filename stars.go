@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"sync"
+	"time"
+)
+
+var starWindow = flag.Duration("star-window", time.Hour, "announce stars at most once per `duration` (0 to announce every star)")
+
+// A StarCounter tallies up star events and announces them in bulk,
+// so that a repository which suddenly becomes popular doesn't flood the channel.
+//
+// The first star for a repository starts the clock;
+// once the window has passed, the net number of stars received
+// since then is announced.
+type StarCounter struct {
+	Window   time.Duration
+	Announce func(d *Destination, msg string)
+
+	mu      sync.Mutex
+	pending map[starKey]*starTally
+}
+
+type starKey struct {
+	dest *Destination
+	repo string
+}
+
+type starTally struct {
+	stars int
+	last  *GHEvent
+}
+
+// Add a star event to the tally.
+// Reports whether the event was counted;
+// if not, it should be announced as usual.
+func (c *StarCounter) Add(d *Destination, eventType string, event *GHEvent) bool {
+	if c == nil || c.Window <= 0 {
+		return false
+	}
+	delta := starDelta(eventType, event)
+	if delta == 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[starKey]*starTally)
+	}
+	key := starKey{d, event.Repository.FullName}
+	t := c.pending[key]
+	if t == nil {
+		t = new(starTally)
+		c.pending[key] = t
+		time.AfterFunc(c.Window, func() { c.flush(key) })
+	}
+	t.stars += delta
+	t.last = event
+	return true
+}
+
+func (c *StarCounter) flush(key starKey) {
+	c.mu.Lock()
+	t := c.pending[key]
+	delete(c.pending, key)
+	c.mu.Unlock()
+
+	if t == nil || t.stars <= 0 {
+		return
+	}
+	msg := FormatStarBurst(t.last, t.stars, c.Window, key.dest.Options)
+	c.Announce(key.dest, msg)
+}
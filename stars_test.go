@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// GitHub sends a star event and a watch event for every star;
+// only one of them should count.
+func TestStarCounterOneStar(t *testing.T) {
+	event, err := ParseGithubEvent([]byte(`{"action":"created","sender":{"login":"alice"},"repository":{"name":"r","full_name":"o/r","stargazers_count":5}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	watch := *event
+	watch.Action = "started"
+
+	var msgs []string
+	c := &StarCounter{
+		Window: time.Hour,
+		Announce: func(d *Destination, msg string) {
+			msgs = append(msgs, msg)
+		},
+	}
+	d := &Destination{Channel: "#main", Options: &EventFormatterOptions{LongURL: true, NoColors: true}}
+	if !c.Add(d, "star", event) {
+		t.Fatal("star event wasn't counted")
+	}
+	if c.Add(d, "watch", &watch) {
+		t.Error("watch event was counted")
+	}
+	if msg := FormatGithubEvent("watch", &watch, d.Options); msg != "" {
+		t.Errorf("watch event was formatted: %q", msg)
+	}
+	c.flush(starKey{d, "o/r"})
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "alice starred the repository") {
+		t.Errorf("got %q, want one star", msgs)
+	}
+
+	// Without a window, each star is announced on its own, once.
+	if msg := FormatGithubEvent("star", event, d.Options); !strings.Contains(msg, "alice starred the repository") {
+		t.Errorf("star event: got %q", msg)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	htmlCommentRE  = regexp.MustCompile(`(?s)<!--.*?(-->|$)`)
+	markdownImgRE  = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLinkRE = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mentionRE      = regexp.MustCompile(`^@[A-Za-z0-9][-A-Za-z0-9/]*[,;:.]?$`)
+	headingRE      = regexp.MustCompile(`^#{1,6}(\s|$)`)
+)
+
+// Sum up a comment in a line.
+//
+// The first line that says something is shown, cut down to width characters
+// (no limit if width <= 0), with "..." if there is more to it.
+// Quotes, code blocks, headings and HTML comments (like the ones issue templates
+// leave behind), images and blank lines are skipped; links are turned into their text,
+// and runs of @mentions are collapsed into one, in the words of lang.
+func summarizeComment(body string, width int, lang *catalog) string {
+	body = htmlCommentRE.ReplaceAllString(body, "")
+	var summary string
+	more := false
+	fence := ""
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fence = line[:3]
+			continue
+		}
+		if strings.HasPrefix(line, ">") || headingRE.MatchString(line) {
+			continue
+		}
+		line = markdownImgRE.ReplaceAllString(line, "")
+		line = markdownLinkRE.ReplaceAllString(line, "$1")
+		line = collapseMentions(strings.Fields(line), lang)
+		if line == "" {
+			continue
+		}
+		if summary != "" {
+			more = true
+			break
+		}
+		summary = line
+	}
+	if more {
+		summary += "..."
+	}
+	return ellipsize(summary, width)
+}
+
+// Join words back into a line, with "@a @b @c" shortened to "@a and 2 others".
+func collapseMentions(words []string, lang *catalog) string {
+	var out []string
+	for i := 0; i < len(words); i++ {
+		n := 0
+		for i+n < len(words) && mentionRE.MatchString(words[i+n]) {
+			n++
+		}
+		if n < 2 {
+			out = append(out, words[i])
+			continue
+		}
+		out = append(out, lang.others(strings.TrimRight(words[i], ",;:."), n-1))
+		i += n - 1
+	}
+	return strings.Join(out, " ")
+}
+
+// Cut s down to at most width characters, at a space if there's one nearby.
+func ellipsize(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	const dots = "..."
+	if width <= len(dots) {
+		return dots[:width]
+	}
+	cut, n := 0, 0
+	for cut = range s {
+		if n == width-len(dots) {
+			break
+		}
+		n++
+	}
+	if space := strings.LastIndex(s[:cut], " "); space > cut/2 {
+		cut = space
+	}
+	return strings.TrimRight(s[:cut], " ,;:.") + dots
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// A Message is what the message templates are executed with.
+// Most templates only need the common fields and the raw Event;
+// the rest are set for push and star messages.
+type Message struct {
+	Type   string   // the event type, like "push"
+	Event  *GHEvent // the payload
+	Repo   string   // the repository, or the user or organization the event happened to
+	Actor  string   // who did it
+	Action string   // what they did
+	URL    string   // where to read more, shortened if need be
+
+	Commits []*GHCommit   // push: the new commits
+	Commit  *GHCommit     // push.commit: the commit being shown
+	Files   int           // push.commit: matching files touched, if Paths is set
+	Count   int           // push.more: commits not shown; star.burst: stars; pull_request.merged: commits merged
+	Newest  bool          // push.more: whether the newest commits were shown
+	Window  time.Duration // star.burst: the time the stars were counted over
+
+	Highlight string // pull_request_review, workflow_run: the nick of someone to highlight
+	Deleted   bool   // pull_request.merged: whether the head branch was deleted
+
+	Digest *DigestTally // digest: what happened; Action is the period, "daily" or "weekly"
+
+	Added   int // edits: the number of lines added to the body
+	Removed int // edits: the number of lines removed from the body
+}
+
+// The default templates.
+// Templates are named after the event type they format;
+// names with a dot are variations of an event or parts of a message.
+// Any of them can be overridden with EventFormatterOptions.Templates.
+var defaultTemplates = map[string]string{
+	"push": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $e := .Event}}{{$n := len .Commits}}
+		{{- if created $e}}
+			{{- if isTag $e}} tagged {{tag (refName $e)}} at {{if $e.BaseRef}}{{branch (baseRefName $e)}}{{else}}{{hash (shortSHA $e.After)}}{{end}}
+			{{- else}} created {{branch (refName $e)}}
+				{{- if $e.BaseRef}} from {{branch (baseRefName $e)}}{{else if eq $n 0}} at {{hash (shortSHA $e.After)}}{{end}}
+				{{- ""}} (+{{bold (print $n)}} new commit{{plural $n "" "s"}})
+			{{- end}}
+		{{- else if deleted $e}} {{warning "deleted"}} {{branch (refName $e)}} at {{hash (shortSHA $e.Before)}}
+		{{- else if forced $e}} {{warning "force-pushed"}} {{branch (refName $e)}} from {{hash (shortSHA $e.Before)}} to {{hash (shortSHA $e.After)}}
+		{{- else if and $e.Commits (eq $n 0)}}
+			{{- if $e.BaseRef}} merged {{branch (baseRefName $e)}} into {{branch (refName $e)}}
+			{{- else}} fast-forwarded {{branch (refName $e)}} from {{hash (shortSHA $e.Before)}} to {{hash (shortSHA $e.After)}}
+			{{- end}}
+		{{- else}} pushed {{bold (print $n)}} new commit{{plural $n "" "s"}} to {{branch (refName $e)}}
+		{{- end}}: {{url .URL}}`,
+
+	"push.commit": `{{repo .Repo}}/{{branch (refName .Event)}} {{hash (shortSHA .Commit.ID)}} {{name .Commit.Author.Name}}
+		{{- $by := committer .Commit}}{{$age := authored .Commit}}
+		{{- if or $by $age}} ({{with $by}}committed by {{name .}}{{if $age}}, {{end}}{{end}}{{with $age}}authored {{.}}{{end}}){{end}}: {{firstLine .Commit.Message}}
+		{{- if .Files}} ({{.Files}} file{{plural .Files "" "s"}}){{end}}`,
+
+	"push.more": `... and {{.Count}} {{if .Newest}}older{{else}}more{{end}} commit{{plural .Count "" "s"}}
+		{{- if .URL}}: {{url .URL}}{{end}}`,
+
+	"commit_comment": `[{{repo .Repo}}] {{name .Actor}} commented on commit {{hash (shortSHA .Event.Comment.CommitID)}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+	"pull_request": `{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} {{$.Action}} pull request #{{.Number}}: {{.Title}}
+		{{- ""}} ({{branch .Base.Ref}}...{{if eq .Head.Ref .Base.Ref}}{{branch .Head.Label}}{{else}}{{branch .Head.Ref}}{{end}})
+		{{- if eq $.Action "edited"}} {{template "edited" $}}{{end}} {{url $.URL}}{{end}}`,
+
+	"pull_request.merged": `{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}} merged pull request #{{.Number}} into {{branch .Base.Ref}}
+		{{- with $.Count}} ({{bold (print .)}} commit{{plural . "" "s"}}){{end}}
+		{{- if $.Deleted}}, {{warning "deleted"}} {{branch .Head.Ref}}{{end}}: {{.Title}} {{url $.URL}}{{end}}`,
+
+	"pull_request_review": `{{with .Highlight}}{{.}}: {{end}}{{with .Event.PullRequest}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- $state := $.Event.Review.State}}
+		{{- if eq $state "approved"}} approved
+		{{- else if eq $state "changes_requested"}} requested changes on
+		{{- else}} reviewed
+		{{- end}} pull request #{{.Number}}{{with summarize $.Event.Review.Body}}: {{.}}{{end}} {{url $.URL}}{{end}}`,
+
+	"pull_request_review_comment": `[{{repo .Repo}}] {{name .Actor}} {{if eq .Action "edited"}}edited comment{{else}}commented{{end}} on pull request #{{.Event.PullRequest.Number}} {{hash (shortSHA .Event.Comment.CommitID)}}
+		{{- if eq .Action "edited"}} {{template "edited" .}}{{end}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+	"issues": `[{{repo .Repo}}] {{name .Actor}} {{.Action}} issue #{{.Event.Issue.Number}}{{if eq .Action "edited"}} {{template "edited" .}}{{end}}: {{.Event.Issue.Title}} {{url .URL}}`,
+
+	"issues.triage": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $e := .Event}}{{$issue := .Event.Issue}}
+		{{- if eq .Action "labeled"}} labeled issue #{{$issue.Number}} as {{with $e.Label}}{{label .Name .Color}}{{end}}
+		{{- else if eq .Action "unlabeled"}} removed label {{with $e.Label}}{{label .Name .Color}}{{end}} from issue #{{$issue.Number}}
+		{{- else if eq .Action "assigned"}} assigned issue #{{$issue.Number}} to {{with $e.Assignee}}{{name .Login}}{{end}}
+		{{- else if eq .Action "unassigned"}} unassigned {{with $e.Assignee}}{{name .Login}}{{end}} from issue #{{$issue.Number}}
+		{{- else if eq .Action "milestoned"}} added issue #{{$issue.Number}} to milestone {{template "issues.milestone" .}}
+		{{- else if eq .Action "demilestoned"}} removed issue #{{$issue.Number}} from milestone {{template "issues.milestone" .}}
+		{{- else if eq .Action "transferred"}} transferred issue #{{$issue.Number}}
+			{{- with $e.Changes}}{{if .NewRepository}} to {{repo .NewRepository.FullName}}{{with .NewIssue}}#{{.Number}}{{end}}{{end}}{{end}}
+		{{- else}} {{.Action}} issue #{{$issue.Number}}
+		{{- end}}: {{$issue.Title}}
+		{{- if and $issue.Milestone (ne .Action "milestoned") (ne .Action "demilestoned")}} (milestone {{bold $issue.Milestone.Title}}){{end}} {{url .URL}}`,
+
+	"issues.milestone": `{{with .Event.Milestone}}{{bold .Title}}{{else}}{{with .Event.Issue.Milestone}}{{bold .Title}}{{else}}{{bold ""}}{{end}}{{end}}`,
+
+	"issue_comment": `[{{repo .Repo}}] {{name .Actor}} {{if eq .Action "edited"}}edited comment{{else}}commented{{end}} on issue #{{.Event.Issue.Number}}
+		{{- if eq .Action "edited"}} {{template "edited" .}}{{end}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+	"discussion": `{{with .Event.Discussion}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- if eq $.Action "created"}} started discussion #{{.Number}}{{if .Category.Name}} in {{.Category.Name}}{{end}}
+		{{- else}} {{$.Action}} discussion #{{.Number}}
+		{{- end}}: {{.Title}} {{url $.URL}}{{end}}`,
+
+	"discussion_comment": `[{{repo .Repo}}] {{name .Actor}} {{if eq .Action "edited"}}edited comment{{else}}commented{{end}} on discussion #{{.Event.Discussion.Number}}
+		{{- if eq .Action "edited"}} {{template "edited" .}}{{end}}{{with summarize .Event.Comment.Body}}: {{.}}{{end}} {{url .URL}}`,
+
+	// How much an edit changed the body of a comment, issue or pull request.
+	"edited": `(+{{.Added}}/-{{.Removed}} lines)`,
+
+	"deployment": `{{with .Event.Deployment}}[{{repo $.Repo}}] {{name $.Actor}} is deploying {{branch .Ref}} ({{hash (shortSHA .SHA)}}) to {{environment $.Event}} {{url $.URL}}{{end}}`,
+
+	"deployment_status": `{{with .Event.Deployment}}[{{repo $.Repo}}] deployment of {{branch .Ref}} ({{hash (shortSHA .SHA)}}) to {{environment $.Event}} by {{name .Creator.Login}}
+		{{- end}}{{with .Event.DeploymentStatus}}: {{state .State}}{{if .Description}} - {{firstLine .Description}}{{end}} {{url $.URL}}{{end}}`,
+
+	"member": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $member := .Event.Member.Login}}
+		{{- if eq .Action "added"}} added {{name $member}} as a collaborator
+		{{- else if eq .Action "removed"}} removed {{name $member}} as a collaborator
+		{{- else}} changed the permissions of collaborator {{name $member}}
+			{{- with .Event.Changes}}{{with .Permission}}{{if .To}} from {{.From}} to {{.To}}{{end}}{{end}}{{end}}
+		{{- end}} {{url .URL}}`,
+
+	"repository": `[{{repo .Repo}}] {{name .Actor}}
+		{{- $c := .Event.Changes}}{{$from := formerOwner .Event}}
+		{{- if and (eq .Action "renamed") $c $c.Repository $c.Repository.Name}} renamed the repository from {{repo $c.Repository.Name.From}} to {{repo .Event.Repository.Name}}
+		{{- else if and (eq .Action "transferred") $from}} transferred the repository from {{name $from}} to {{name .Event.Repository.Owner.Login}}
+		{{- else if eq .Action "publicized"}} made the repository {{bold "public"}}
+		{{- else if eq .Action "privatized"}} made the repository {{bold "private"}}
+		{{- else}} {{.Action}} the repository
+		{{- end}} {{url .URL}}`,
+
+	"public": `[{{repo .Repo}}] {{name .Actor}} made the repository {{bold "public"}} {{url .URL}}`,
+
+	"branch_protection_rule": `[{{repo .Repo}}] {{name .Actor}} {{.Action}} the branch protection rule for {{branch .Event.Rule.Name}} {{url .URL}}`,
+
+	"team_add": `[{{repo .Repo}}] {{name .Actor}} gave team {{name .Event.Team.Name}} access to the repository {{url .URL}}`,
+
+	"star": `{{$n := .Event.Repository.StargazersCount}}[{{repo .Repo}}] {{name .Actor}} starred the repository (now {{count $n}} star{{plural $n "" "s"}}) {{url .URL}}`,
+
+	"star.burst": `[{{repo .Repo}}] {{bold (printf "%+d" .Count)}} star{{plural .Count "" "s"}} in the last {{window .Window}} (now {{count .Event.Repository.StargazersCount}}) {{url .URL}}`,
+
+	"digest": `{{if eq .Action "weekly"}}Last week{{else}}Yesterday{{end}} in {{repo .Repo}}
+		{{- $sep := ": "}}{{with .Digest}}{{$people := len .Authors}}
+		{{- with .Commits}}{{$sep}}{{bold (print .)}} commit{{plural . "" "s"}} by {{$people}} {{plural $people "person" "people"}}{{$sep = ", "}}{{end}}
+		{{- with .PullsMerged}}{{$sep}}{{.}} PR{{plural . "" "s"}} merged{{$sep = ", "}}{{end}}
+		{{- with .PullsOpened}}{{$sep}}{{.}} PR{{plural . "" "s"}} opened{{$sep = ", "}}{{end}}
+		{{- with .IssuesOpened}}{{$sep}}{{.}} issue{{plural . "" "s"}} opened{{$sep = ", "}}{{end}}
+		{{- with .IssuesClosed}}{{$sep}}{{.}} issue{{plural . "" "s"}} closed{{$sep = ", "}}{{end}}
+		{{- with .Releases}}{{$sep}}{{.}} release{{plural . "" "s"}}{{$sep = ", "}}{{end}}
+		{{- end}}`,
+
+	"sponsorship": `[{{repo .Repo}}] {{template "sponsorship.sponsor" .}}
+		{{- if eq .Action "created"}} is now sponsoring {{name .Repo}}{{template "sponsorship.tier" .}}
+		{{- else if eq .Action "tier_changed"}} changed their sponsorship of {{name .Repo}}{{template "sponsorship.tier" .}}
+		{{- else}} {{.Action}} their sponsorship of {{name .Repo}}
+		{{- end}} {{url .URL}}`,
+
+	"sponsorship.sponsor": `{{if eq .Event.Sponsorship.PrivacyLevel "private"}}a private sponsor{{else}}{{name .Actor}}{{end}}`,
+
+	"sponsorship.tier": `{{with .Event.Sponsorship.Tier}}{{if .Name}} ({{.Name}}){{else if gt .MonthlyPriceInDollars 0}} (${{.MonthlyPriceInDollars}} a month){{end}}{{end}}`,
+
+	"milestone": `{{with .Event.Milestone}}[{{repo $.Repo}}] {{name $.Actor}} {{$.Action}} milestone {{bold .Title}}
+		{{- if and (ge (len .DueOn) 10) (ne $.Action "closed") (ne $.Action "deleted")}} (due {{slice .DueOn 0 10}}){{end}} {{url $.URL}}{{end}}`,
+
+	"label": `{{with .Event.Label}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- if eq $.Action "edited"}} renamed label {{$.Event.Changes.Name.From}} to {{label .Name .Color}}
+		{{- else}} {{$.Action}} label {{label .Name .Color}}
+		{{- end}} {{url $.URL}}{{end}}`,
+
+	"organization": `[{{repo .Repo}}] {{name .Actor}}
+		{{- if eq .Action "member_added"}}{{with .Event.Membership}} added {{name .User.Login}} to the organization{{if eq .Role "admin"}} as an {{bold "owner"}}{{end}}{{end}}
+		{{- else if eq .Action "member_removed"}}{{with .Event.Membership}} removed {{name .User.Login}} from the organization{{end}}
+		{{- else if eq .Action "member_invited"}}{{with .Event.Invitation}} invited {{if .Login}}{{name .Login}}{{else}}someone{{end}} to the organization{{end}}
+		{{- else if eq .Action "renamed"}} renamed the organization{{with .Event.Changes}}{{with .Login}} from {{name .From}}{{end}}{{end}}
+		{{- else}} {{humanize .Action}} the organization
+		{{- end}} {{url .URL}}`,
+
+	"membership": `[{{repo .Repo}}] {{name .Actor}}
+		{{- if eq .Action "added"}} added {{name .Event.Member.Login}} to
+		{{- else}} removed {{name .Event.Member.Login}} from
+		{{- end}} team {{name .Event.Team.Name}} {{url .URL}}`,
+
+	"installation": `{{with .Event.Installation}}[{{repo $.Repo}}] {{name $.Actor}}
+		{{- if eq $.Action "created"}} installed
+		{{- else if eq $.Action "deleted"}} uninstalled
+		{{- else if eq $.Action "suspend"}} suspended
+		{{- else if eq $.Action "unsuspend"}} unsuspended
+		{{- else if eq $.Action "new_permissions_accepted"}} accepted new permissions for
+		{{- else}} {{humanize $.Action}}
+		{{- end}} the {{bold .AppSlug}} app
+		{{- if eq $.Action "created"}}{{if eq .RepositorySelection "all"}} on all repositories{{else}}{{with len $.Event.Repositories}} on {{.}} repositor{{plural . "y" "ies"}}{{end}}{{end}}{{end}} {{url $.URL}}{{end}}`,
+
+	"projects_v2_item": `[{{repo .Repo}}] {{name .Actor}}
+		{{- if eq .Action "created"}} added {{template "projects_v2_item.kind" .}} to {{template "projects_v2_item.project" .}}
+		{{- else if eq .Action "deleted"}} removed {{template "projects_v2_item.kind" .}} from {{template "projects_v2_item.project" .}}
+		{{- else if eq .Action "converted"}} converted a draft issue into an issue in {{template "projects_v2_item.project" .}}
+		{{- else if eq .Action "edited"}}{{with .Event.Changes.FieldValue}} changed {{or .FieldName "a field"}} of {{template "projects_v2_item.kind" $}} in {{template "projects_v2_item.project" $}}
+			{{- with fieldValue .To}}{{$to := .}}{{with fieldValue $.Event.Changes.FieldValue.From}} from {{.}}{{end}} to {{bold $to}}{{end}}{{end}}
+		{{- else}} {{.Action}} {{template "projects_v2_item.kind" .}} in {{template "projects_v2_item.project" .}}
+		{{- end}} {{url .URL}}`,
+
+	"projects_v2_item.kind": `{{$t := .Event.ProjectsV2Item.ContentType}}
+		{{- if eq $t "Issue"}}an issue
+		{{- else if eq $t "PullRequest"}}a pull request
+		{{- else if eq $t "DraftIssue"}}a draft issue
+		{{- else}}an item
+		{{- end}}`,
+
+	"projects_v2_item.project": `{{with projectNumber .Event}}project #{{.}}{{else}}a project{{end}}`,
+
+	"gollum": `[{{.Repo}}] {{.Actor}}
+		{{- if eq (len .Event.Pages) 1}}{{with index .Event.Pages 0}} {{.Action}} wiki page {{.Title}}{{if .Summary}}: {{.Summary}}{{end}}{{end}}
+		{{- else}} {{toSentence (pageCounts .Event.Pages)}} wiki pages
+		{{- end}} {{url .URL}}`,
+
+	"dependabot_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} {{alertVerb $.Action}} Dependabot alert #{{.Number}}:
+		{{- with .SecurityAdvisory}}{{if .Severity}} [{{severity .Severity}}]{{end}}{{end}}
+		{{- with .Dependency}}{{if .Package.Name}} {{branch (packageName .Package)}}{{end}}{{end}}
+		{{- with .SecurityAdvisory}}{{if .Summary}}: {{firstLine .Summary}}{{end}}{{end}}
+		{{- if .State}} (now {{.State}}){{end}} {{url $.URL}}{{end}}`,
+
+	"code_scanning_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} {{alertVerb $.Action}} code scanning alert #{{.Number}}:
+		{{- $tool := ""}}{{with .Tool}}{{if .Name}}{{$tool = printf "%s " .Name}}{{end}}{{end}}
+		{{- with .Rule}}
+			{{- with or .SecuritySeverityLevel .Severity}} [{{severity .}}]{{end}} {{branch (print $tool .ID)}}
+			{{- if .Description}}: {{firstLine .Description}}{{end}}
+		{{- end}}
+		{{- if .State}} (now {{.State}}){{end}} {{url $.URL}}{{end}}`,
+
+	"secret_scanning_alert": `{{with .Event.Alert}}[{{repo $.Repo}}] {{name $.Actor}} {{alertVerb $.Action}} secret scanning alert #{{.Number}}
+		{{- with or .SecretTypeDisplayName .SecretType}}: {{branch .}}{{end}}
+		{{- if .State}} (now {{.State}}{{if .Resolution}} as {{humanize .Resolution}}{{end}}){{end}} {{url $.URL}}{{end}}`,
+
+	"repository_advisory": `{{with .Event.RepositoryAdvisory}}[{{repo $.Repo}}] {{name $.Actor}} {{alertVerb $.Action}} security advisory {{.GhsaID}}{{if .CveID}} ({{.CveID}}){{end}}:
+		{{- if .Severity}} [{{severity .Severity}}]{{end}}
+		{{- if .Summary}} {{firstLine .Summary}}{{end}}
+		{{- if .State}} (now {{.State}}){{end}} {{url $.URL}}{{end}}`,
+
+	"workflow_run": `{{with .Highlight}}{{.}}: {{end}}{{with .Event.WorkflowRun}}[{{repo $.Repo}}] {{.Name}}{{if .RunNumber}} #{{.RunNumber}}{{end}}
+		{{- if eq .Conclusion "timed_out"}} {{warning "timed out"}}{{else}} {{warning "failed"}}{{end}} on {{branch .HeadBranch}}
+		{{- range .PullRequests}} (pull request #{{.Number}}){{end}}{{with .HeadSHA}} ({{hash (shortSHA .)}}){{end}} {{url $.URL}}{{end}}`,
+}
+
+// Functions available to templates, marking up text with r.
+func (data *EventFormatterOptions) templateFuncs(r Renderer) template.FuncMap {
+	style := func(element string) func(interface{}) styled {
+		return func(v interface{}) styled { return markup(r, element, v) }
+	}
+	lang := data.catalog()
+	return template.FuncMap{
+		"url":     style("url"),
+		"repo":    style("repo"),
+		"branch":  style("branch"),
+		"tag":     style("tag"),
+		"hash":    style("hash"),
+		"bold":    style("bold"),
+		"warning": style("warning"),
+		"name": func(v interface{}) styled {
+			if s, ok := v.(string); ok {
+				v = data.nick(s)
+			}
+			return markup(r, "name", v)
+		},
+		"severity": func(s string) styled {
+			return styled(r.Style(severityStyle(s), lang.tr(s)))
+		},
+		"state": func(s string) styled {
+			return styled(r.Style(stateStyle(s), lang.tr(s)))
+		},
+		"label": func(name, color string) styled {
+			return styled(r.Color(name, color))
+		},
+		"_escape": func(v interface{}) string {
+			if s, ok := v.(styled); ok {
+				return string(s)
+			}
+			return r.Text(fmt.Sprint(v))
+		},
+
+		"count":      lang.count,
+		"window":     lang.window,
+		"firstLine":  firstLineOf,
+		"summarize":  func(body string) string { return summarizeComment(body, data.commentWidth(), lang) },
+		"shortSHA":   shortSHA,
+		"plural":     lang.pluralForm,
+		"toSentence": lang.toSentence,
+		"tr":         lang.tr,
+		"alertVerb":  alert_verb,
+		"humanize":   func(s string) string { return strings.Replace(s, "_", " ", -1) },
+
+		"created":       func(event *GHEvent) bool { return event.created() },
+		"deleted":       func(event *GHEvent) bool { return event.deleted() },
+		"forced":        func(event *GHEvent) bool { return event.forced() },
+		"isTag":         func(event *GHEvent) bool { _, ok := event.tag(); return ok },
+		"refName":       func(event *GHEvent) string { return event.ref_name() },
+		"baseRefName":   func(event *GHEvent) string { return event.base_ref_name() },
+		"environment":   func(event *GHEvent) string { return event.Deployment.environment(event) },
+		"formerOwner":   formerOwner,
+		"projectNumber": projectNumber,
+		"fieldValue":    fieldValueString,
+		"packageName":   packageName,
+		"pageCounts":    func(pages []GHPage) []string { return pageCounts(pages, lang) },
+		"committer":     func(commit *GHCommit) string { return commit.otherCommitter() },
+		"authored":      data.commitAge,
+	}
+}
+
+var baseTemplates = template.Must(parseTemplates(template.New("").Funcs((&EventFormatterOptions{}).templateFuncs(ircRenderer{})), defaultTemplates))
+
+// Parse templates into t, and hook up the escaping of their output.
+func parseTemplates(t *template.Template, texts map[string]string) (*template.Template, error) {
+	old := make(map[*parse.Tree]bool)
+	for _, x := range t.Templates() {
+		old[x.Tree] = true
+	}
+	for name, text := range texts {
+		if _, err := t.New(name).Parse(text); err != nil {
+			return nil, err
+		}
+	}
+	for _, x := range t.Templates() {
+		if x.Tree != nil && !old[x.Tree] {
+			escapeActions(x.Tree.Root)
+		}
+	}
+	return t, nil
+}
+
+// Parsed templates for options with custom Templates.
+var templateCache = struct {
+	sync.Mutex
+	m map[*EventFormatterOptions]*template.Template
+}{m: make(map[*EventFormatterOptions]*template.Template)}
+
+// The templates to format messages with:
+// the default templates in the language of data.Locale,
+// overridden by any in data.Templates.
+func (data *EventFormatterOptions) templates() (*template.Template, error) {
+	base := data.catalog().base
+	if len(data.Templates) == 0 {
+		return base, nil
+	}
+	templateCache.Lock()
+	defer templateCache.Unlock()
+	if t, ok := templateCache.m[data]; ok {
+		return t, nil
+	}
+	t, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	t, err = parseTemplates(t, data.Templates)
+	if err != nil {
+		return nil, err
+	}
+	templateCache.m[data] = t
+	return t, nil
+}
+
+// Templates ready to execute for a set of options,
+// with their functions bound to the options and renderer.
+type boundTemplates struct {
+	t *template.Template
+	r Renderer
+}
+
+var boundTemplateCache = struct {
+	sync.Mutex
+	m map[*EventFormatterOptions]*boundTemplates
+}{m: make(map[*EventFormatterOptions]*boundTemplates)}
+
+// The templates and renderer to format messages with.
+// They're made once for each set of options, which shouldn't change after that.
+func (data *EventFormatterOptions) bound() (*boundTemplates, error) {
+	boundTemplateCache.Lock()
+	defer boundTemplateCache.Unlock()
+	if b, ok := boundTemplateCache.m[data]; ok {
+		return b, nil
+	}
+	t, err := data.templates()
+	if err != nil {
+		return nil, err
+	}
+	t, err = t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	r := data.renderer()
+	t.Funcs(data.templateFuncs(r))
+	b := &boundTemplates{t, r}
+	boundTemplateCache.m[data] = b
+	return b, nil
+}
+
+// Make sure the custom templates parse.
+func (data *EventFormatterOptions) checkTemplates() error {
+	for name := range data.Templates {
+		if _, ok := defaultTemplates[name]; !ok {
+			return fmt.Errorf("unknown template %q", name)
+		}
+	}
+	_, err := data.templates()
+	return err
+}
+
+// Create a message about an event, linking to url.
+func (data *EventFormatterOptions) newMessage(eventType string, event *GHEvent, url string) *Message {
+	return &Message{
+		Type:   eventType,
+		Event:  event,
+		Repo:   data.repoName(event),
+		Actor:  event.Sender.Login,
+		Action: event.Action,
+		URL:    url,
+	}
+}
+
+// Format a message with the named template.
+func (data *EventFormatterOptions) render(name string, msg *Message) string {
+	return data.finish(msg, data.execute(name, msg))
+}
+
+// Execute the named template, to produce one line of a message.
+// Errors are logged, and result in an empty line.
+func (data *EventFormatterOptions) execute(name string, msg *Message) string {
+	bound, err := data.bound()
+	if err != nil {
+		log.Printf("error parsing templates: %v", err)
+		return ""
+	}
+	var b strings.Builder
+	if err := bound.t.ExecuteTemplate(&b, name, msg); err != nil {
+		log.Printf("error formatting %s event: %v", msg.Type, err)
+		return ""
+	}
+	return b.String()
+}
+
+// Put together the lines of a message.
+// Empty lines are dropped; if the first line is empty, so is the message.
+func (data *EventFormatterOptions) finish(msg *Message, lines ...string) string {
+	if len(lines) == 0 || lines[0] == "" {
+		return ""
+	}
+	var nonempty []string
+	for _, line := range lines {
+		if line != "" {
+			nonempty = append(nonempty, line)
+		}
+	}
+	r := data.renderer()
+	if bound, err := data.bound(); err == nil {
+		r = bound.r
+	}
+	return r.Finish(msg, nonempty)
+}
+
+// The previous owner of a transferred repository.
+func formerOwner(event *GHEvent) string {
+	if c := event.Changes; c != nil && c.Owner != nil {
+		if c.Owner.From.Organization != nil {
+			return c.Owner.From.Organization.Login
+		}
+		if c.Owner.From.User != nil {
+			return c.Owner.From.User.Login
+		}
+	}
+	return ""
+}
+
+// The number of the project a project item event is about, if known.
+func projectNumber(event *GHEvent) int {
+	if c := event.Changes; c != nil && c.FieldValue != nil {
+		return c.FieldValue.ProjectNumber
+	}
+	return 0
+}
+
+func packageName(pkg GHPackage) string {
+	if pkg.Ecosystem != "" {
+		return pkg.Ecosystem + "/" + pkg.Name
+	}
+	return pkg.Name
+}
+
+// Counts the wiki pages by action, like "created 1" and "edited 2".
+func pageCounts(pages []GHPage, lang *catalog) []string {
+	var counts = make(map[string]int)
+	for i := range pages {
+		counts[pages[i].Action] += 1
+	}
+
+	var actions []string
+	for action, count := range counts {
+		actions = append(actions, fmt.Sprintf("%s %d", lang.tr(action), count))
+	}
+	sort.Strings(actions)
+	return actions
+}
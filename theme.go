@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/// IRC message formatting.  For reference:
+/// \002 bold   \003 color   \004 hex color   \017 reset
+/// \026 reverse   \035 italic   \037 underline
+/// 0 white           1 black         2 dark blue         3 dark green
+/// 4 dark red        5 brownish      6 dark purple       7 orange
+/// 8 yellow          9 light green   10 dark teal        11 light teal
+/// 12 light blue     13 light purple 14 dark gray        15 light gray
+
+// Color themes for IRC output.
+// Each one maps the elements of a message (see Renderer.Style)
+// to an IRC style; see parseIRCStyle for the syntax.
+// The "label" element can also be "palette", to show labels
+// in the nearest mIRC color, or "hex" to show them in their exact color.
+var ircThemes = map[string]map[string]string{
+	"default": {
+		"url":      "navy underline",
+		"repo":     "pink",
+		"name":     "silver",
+		"branch":   "purple",
+		"tag":      "purple",
+		"hash":     "grey",
+		"bold":     "bold",
+		"warning":  "red",
+		"critical": "bold red",
+		"high":     "red",
+		"medium":   "orange",
+		"low":      "teal",
+		"success":  "green",
+		"failure":  "red",
+		"pending":  "orange",
+		"inactive": "grey",
+		"label":    "palette",
+	},
+	// For clients with a dark background,
+	// where dark blue, dark purple and dark gray are hard to read.
+	"dark": {
+		"url":      "blue underline",
+		"repo":     "pink",
+		"name":     "silver",
+		"branch":   "cyan",
+		"tag":      "cyan",
+		"hash":     "teal",
+		"bold":     "bold",
+		"warning":  "red",
+		"critical": "bold red",
+		"high":     "red",
+		"medium":   "orange",
+		"low":      "cyan",
+		"success":  "lime",
+		"failure":  "red",
+		"pending":  "orange",
+		"inactive": "silver",
+		"label":    "palette",
+	},
+	"high-contrast": {
+		"url":      "underline",
+		"repo":     "bold",
+		"name":     "bold",
+		"branch":   "bold",
+		"tag":      "bold",
+		"bold":     "bold",
+		"warning":  "bold red",
+		"critical": "bold reverse",
+		"high":     "bold red",
+		"medium":   "bold orange",
+		"low":      "bold",
+		"success":  "bold green",
+		"failure":  "bold red",
+		"pending":  "bold orange",
+	},
+	"bold-only": {
+		"repo":     "bold",
+		"bold":     "bold",
+		"warning":  "bold",
+		"critical": "bold",
+		"high":     "bold",
+		"failure":  "bold",
+	},
+}
+
+// Names for the mIRC colors.
+var ircColorNames = map[string]int{
+	"white": 0, "black": 1, "navy": 2, "green": 3,
+	"red": 4, "maroon": 5, "purple": 6, "orange": 7,
+	"yellow": 8, "lime": 9, "teal": 10, "cyan": 11,
+	"blue": 12, "pink": 13, "grey": 14, "silver": 15,
+}
+
+// Turn a style like "bold red" into IRC formatting codes.
+//
+// A style is a list of words separated by spaces.
+// The words bold, italic, underline and reverse turn on those attributes.
+// Anything else is a color: a mIRC color number or name (see ircColorNames),
+// or a hex color like #ff8800, which needs a client that understands \x04 codes.
+// A background color can follow the foreground after a comma, like "white,red".
+func parseIRCStyle(style string) (string, error) {
+	var b strings.Builder
+	for _, word := range strings.Fields(style) {
+		switch word {
+		case "bold":
+			b.WriteString("\002")
+		case "italic":
+			b.WriteString("\035")
+		case "underline":
+			b.WriteString("\037")
+		case "reverse":
+			b.WriteString("\026")
+		case "none":
+		default:
+			fg, bg := partition(word, ",")
+			if strings.HasPrefix(fg, "#") {
+				fg, bg = fg[1:], strings.TrimPrefix(bg, "#")
+				if !isHexColor(fg) || (bg != "" && !isHexColor(bg)) {
+					return "", fmt.Errorf("bad hex color %q", word)
+				}
+				b.WriteString("\004" + fg)
+				if bg != "" {
+					b.WriteString("," + bg)
+				}
+				continue
+			}
+			c, ok := ircColor(fg)
+			if !ok {
+				return "", fmt.Errorf("unknown color %q", fg)
+			}
+			fmt.Fprintf(&b, "\003%02d", c)
+			if bg != "" {
+				c, ok := ircColor(bg)
+				if !ok {
+					return "", fmt.Errorf("unknown color %q", bg)
+				}
+				fmt.Fprintf(&b, ",%02d", c)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+func ircColor(s string) (int, bool) {
+	if c, ok := ircColorNames[s]; ok {
+		return c, true
+	}
+	c, err := strconv.Atoi(s)
+	return c, err == nil && c >= 0 && c <= 99
+}
+
+func isHexColor(s string) bool {
+	_, ok := nearestColor(s)
+	return ok
+}
+
+// The IRC formatting codes for each element,
+// from the theme with any overrides applied.
+func (data *EventFormatterOptions) ircTheme() (map[string]string, error) {
+	name := data.Theme
+	if name == "" {
+		name = "default"
+	}
+	theme, ok := ircThemes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown Theme %q", name)
+	}
+	styles := make(map[string]string)
+	for element, style := range theme {
+		styles[element] = style
+	}
+	for element, style := range data.Colors {
+		styles[element] = style
+	}
+	codes := make(map[string]string)
+	for element, style := range styles {
+		if element == "label" && (style == "palette" || style == "hex") {
+			codes[element] = style
+			continue
+		}
+		c, err := parseIRCStyle(style)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", element, err)
+		}
+		codes[element] = c
+	}
+	return codes, nil
+}
+
+// IRC formatting, with mIRC colors.
+type ircRenderer struct {
+	styles map[string]string // formatting codes for each element
+}
+
+func (data *EventFormatterOptions) ircRenderer() ircRenderer {
+	styles, err := data.ircTheme()
+	if err != nil {
+		styles, _ = (&EventFormatterOptions{}).ircTheme()
+	}
+	return ircRenderer{styles}
+}
+
+func (ircRenderer) Text(s string) string { return s }
+
+func (r ircRenderer) Style(element, s string) string {
+	codes := r.styles[element]
+	if codes == "" {
+		return s
+	}
+	return codes + s + "\017"
+}
+
+// Labels are shown in (roughly) their own color.
+func (r ircRenderer) Color(s, hex string) string {
+	switch r.styles["label"] {
+	case "palette":
+		if c, ok := nearestColor(hex); ok {
+			return fmt.Sprintf("\003%02d%s\017", c, s)
+		}
+		return s
+	case "hex":
+		// Send the nearest mIRC color too, for clients that don't do hex.
+		if c, ok := nearestColor(hex); ok {
+			return fmt.Sprintf("\003%02d\004%s%s\017", c, hex, s)
+		}
+		return s
+	default:
+		return r.Style("label", s)
+	}
+}
+
+func (ircRenderer) Finish(m *Message, lines []string) string {
+	return strings.Join(lines, "\n")
+}
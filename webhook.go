@@ -19,14 +19,20 @@ type Webhook struct {
 	Secret  []byte
 }
 
-type WebhookHandler func(event string, body []byte)
+// A WebhookHandler is called with the event type,
+// the unique ID of the delivery, and the payload.
+type WebhookHandler func(event string, delivery string, body []byte)
 
 func (h *Webhook) Serve(l net.Listener) error {
+	return serveHTTP(l, h, h.Logger)
+}
+
+func serveHTTP(l net.Listener, handler http.Handler, logger *log.Logger) error {
 	srv := &http.Server{
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
-		Handler:      h,
-		ErrorLog:     h.Logger,
+		Handler:      handler,
+		ErrorLog:     logger,
 	}
 	return srv.Serve(l)
 }
@@ -88,7 +94,7 @@ func (h *Webhook) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if h.Handler != nil {
-		h.Handler(event, body)
+		h.Handler(event, req.Header.Get("X-GitHub-Delivery"), body)
 	}
 }
 